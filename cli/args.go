@@ -0,0 +1,353 @@
+package cli
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+)
+
+// ArgType 位置参数的值类型
+type ArgType int
+
+const (
+	ArgString ArgType = iota
+	ArgInt
+	ArgFloat
+	ArgBool
+	ArgDuration
+)
+
+func (t ArgType) String() string {
+	switch t {
+	case ArgInt:
+		return "int"
+	case ArgFloat:
+		return "float"
+	case ArgBool:
+		return "bool"
+	case ArgDuration:
+		return "duration"
+	default:
+		return "string"
+	}
+}
+
+// Arg 声明一个位置参数
+// 可选参数必须位于必选参数之后，Variadic 只允许出现在最后一个参数上
+type Arg struct {
+	Name     string
+	Usage    string
+	Type     ArgType
+	Optional bool
+	Choices  []string
+
+	// Variadic 接收剩余的全部参数，Min/Max 限制其数量，Max 为 0 表示不限制
+	Variadic bool
+	Min      int
+	Max      int
+}
+
+func (a Arg) synopsis() string {
+	switch {
+	case a.Variadic && a.Min > 0:
+		return "<" + a.Name + ">..."
+	case a.Variadic:
+		return "[" + a.Name + "...]"
+	case a.Optional:
+		return "[" + a.Name + "]"
+	default:
+		return "<" + a.Name + ">"
+	}
+}
+
+func (a Arg) parse(s string) (any, error) {
+	if len(a.Choices) > 0 && !slices.Contains(a.Choices, s) {
+		return nil, fmt.Errorf("must be one of: %s", strings.Join(a.Choices, "|"))
+	}
+
+	switch a.Type {
+	case ArgInt:
+		return strconv.Atoi(s)
+	case ArgFloat:
+		return strconv.ParseFloat(s, 64)
+	case ArgBool:
+		return strconv.ParseBool(s)
+	case ArgDuration:
+		return time.ParseDuration(s)
+	default:
+		return s, nil
+	}
+}
+
+// ArgsError 位置参数校验失败
+type ArgsError struct {
+	Cmd string
+	Msg string
+}
+
+func (e *ArgsError) Error() string {
+	return fmt.Sprintf("invalid arguments for %q: %s", e.Cmd, e.Msg)
+}
+
+// ExitCode implements ExitCoder.
+func (e *ArgsError) ExitCode() int {
+	return ExitUsage
+}
+
+type argSpecs []Arg
+
+func (specs argSpecs) check() error {
+	optional := false
+	for i, a := range specs {
+		if a.Name == "" {
+			return fmt.Errorf("argument #%d has no name", i)
+		}
+		if a.Variadic && i != len(specs)-1 {
+			return fmt.Errorf("variadic argument %q must be the last one", a.Name)
+		}
+		if a.Variadic && a.Max > 0 && a.Max < a.Min {
+			return fmt.Errorf("variadic argument %q has max %d less than min %d", a.Name, a.Max, a.Min)
+		}
+		if !a.Optional && !(a.Variadic && a.Min == 0) && optional {
+			return fmt.Errorf("required argument %q follows an optional one", a.Name)
+		}
+		optional = optional || a.Optional || (a.Variadic && a.Min == 0)
+	}
+	return nil
+}
+
+func (specs argSpecs) bounds() (lo, hi int) {
+	for _, a := range specs {
+		switch {
+		case a.Variadic:
+			lo += a.Min
+			if a.Max == 0 {
+				return lo, -1
+			}
+			hi += a.Max
+		case a.Optional:
+			hi++
+		default:
+			lo++
+			hi++
+		}
+	}
+	return lo, hi
+}
+
+func (specs argSpecs) synopsis() string {
+	parts := make([]string, 0, len(specs))
+	for _, a := range specs {
+		parts = append(parts, a.synopsis())
+	}
+	return strings.Join(parts, " ")
+}
+
+// usages 返回帮助信息中 Arguments 段落的内容
+func (specs argSpecs) usages() string {
+	width := 0
+	for _, a := range specs {
+		width = max(width, len(a.Name))
+	}
+
+	var sb strings.Builder
+	for _, a := range specs {
+		usage := a.Usage
+		if a.Type != ArgString {
+			usage = strings.TrimSpace(usage + " (" + a.Type.String() + ")")
+		}
+		if len(a.Choices) > 0 {
+			usage = strings.TrimSpace(usage + " (one of: " + strings.Join(a.Choices, "|") + ")")
+		}
+		fmt.Fprintf(&sb, "  %-*s   %s\n", width, a.Name, usage)
+	}
+	return sb.String()
+}
+
+func (specs argSpecs) validate(cmd string, args []string) (*ArgValues, error) {
+	lo, hi := specs.bounds()
+	switch {
+	case len(args) < lo:
+		return nil, &ArgsError{Cmd: cmd, Msg: fmt.Sprintf("requires at least %d arg(s), only received %d", lo, len(args))}
+	case hi >= 0 && len(args) > hi:
+		return nil, &ArgsError{Cmd: cmd, Msg: fmt.Sprintf("accepts at most %d arg(s), received %d", hi, len(args))}
+	}
+
+	values := &ArgValues{values: make(map[string]any, len(specs))}
+	for i, a := range specs {
+		if a.Variadic {
+			rest := make([]any, 0, len(args)-i)
+			for _, s := range args[min(i, len(args)):] {
+				v, err := a.parse(s)
+				if err != nil {
+					return nil, &ArgsError{Cmd: cmd, Msg: fmt.Sprintf("%s %q: %v", a.Name, s, err)}
+				}
+				rest = append(rest, v)
+			}
+			values.values[a.Name] = rest
+			break
+		}
+		if i >= len(args) {
+			break
+		}
+		v, err := a.parse(args[i])
+		if err != nil {
+			return nil, &ArgsError{Cmd: cmd, Msg: fmt.Sprintf("%s %q: %v", a.Name, args[i], err)}
+		}
+		values.values[a.Name] = v
+	}
+
+	return values, nil
+}
+
+// ArgValues 保存按 Arg 声明解析后的位置参数
+type ArgValues struct {
+	values map[string]any
+}
+
+type argsContext int
+
+const argsKey argsContext = iota
+
+// ArgsFrom 从 context 中获取解析后的位置参数，未声明 Arg 时返回空的 ArgValues
+func ArgsFrom(ctx context.Context) *ArgValues {
+	if ctx != nil {
+		if v, ok := ctx.Value(argsKey).(*ArgValues); ok {
+			return v
+		}
+	}
+	return &ArgValues{}
+}
+
+// Has 判断参数是否被提供
+func (a *ArgValues) Has(name string) bool {
+	_, ok := a.values[name]
+	return ok
+}
+
+// Get 返回参数的原始解析值，Variadic 参数返回 []any
+func (a *ArgValues) Get(name string) any {
+	return a.values[name]
+}
+
+func (a *ArgValues) String(name string) string {
+	v, _ := a.values[name].(string)
+	return v
+}
+
+func (a *ArgValues) Int(name string) int {
+	v, _ := a.values[name].(int)
+	return v
+}
+
+func (a *ArgValues) Float(name string) float64 {
+	v, _ := a.values[name].(float64)
+	return v
+}
+
+func (a *ArgValues) Bool(name string) bool {
+	v, _ := a.values[name].(bool)
+	return v
+}
+
+func (a *ArgValues) Duration(name string) time.Duration {
+	v, _ := a.values[name].(time.Duration)
+	return v
+}
+
+// Strings 返回 Variadic 字符串参数
+func (a *ArgValues) Strings(name string) []string {
+	return variadic[string](a, name)
+}
+
+// Ints 返回 Variadic 整数参数
+func (a *ArgValues) Ints(name string) []int {
+	return variadic[int](a, name)
+}
+
+func variadic[T any](a *ArgValues, name string) []T {
+	rest, _ := a.values[name].([]any)
+	out := make([]T, 0, len(rest))
+	for _, v := range rest {
+		if t, ok := v.(T); ok {
+			out = append(out, t)
+		}
+	}
+	return out
+}
+
+// positionalArgs 组合 Command.Args 与 Arg 声明，校验通过后将解析结果写入 context
+// 同时在参数解析处完成 cobra 的未知命令与必需 flag 检查，使这些错误以 UsageError 返回；
+// flag 的环境变量在检查之前应用，使仅由环境变量设置的必需 flag 同样通过检查
+func (cb *commandBuilder) positionalArgs() cobra.PositionalArgs {
+	ac := optional[ArgsCommander](cb.commander)
+	validator := ac.ArgsValidator()
+	specs := argSpecs(ac.ArgSpecs())
+
+	return func(cmd *cobra.Command, args []string) error {
+		if err := applyFlagEnv(cmd.Flags()); err != nil {
+			return err
+		}
+		if validator == nil && len(specs) == 0 {
+			if err := unknownCommand(cmd, args); err != nil {
+				return err
+			}
+		}
+		if err := requiredFlags(cmd); err != nil {
+			return err
+		}
+
+		if validator != nil {
+			if err := validator(cmd, args); err != nil {
+				return &ArgsError{Cmd: cmd.CommandPath(), Msg: err.Error()}
+			}
+		}
+		if len(specs) == 0 {
+			return nil
+		}
+
+		values, err := specs.validate(cmd.CommandPath(), args)
+		if err != nil {
+			return err
+		}
+		cmd.SetContext(context.WithValue(cmd.Context(), argsKey, values))
+		return nil
+	}
+}
+
+// unknownCommand 与 cobra 未设置 Args 时的检查一致: 未声明位置参数的根命令有子命令时不接受位置参数
+func unknownCommand(cmd *cobra.Command, args []string) error {
+	if cmd.HasParent() || !cmd.HasSubCommands() || len(args) == 0 {
+		return nil
+	}
+
+	var suggestions strings.Builder
+	if !cmd.DisableSuggestions {
+		if s := cmd.SuggestionsFor(args[0]); len(s) > 0 {
+			suggestions.WriteString("\n\nDid you mean this?\n")
+			for _, name := range s {
+				fmt.Fprintf(&suggestions, "\t%v\n", name)
+			}
+		}
+	}
+	return &UsageError{
+		Cmd: cmd.CommandPath(),
+		Err: fmt.Errorf("unknown command %q for %q%s", args[0], cmd.CommandPath(), suggestions.String()),
+	}
+}
+
+// requiredFlags 提前执行 cobra 的必需 flag 与 flag 分组检查
+func requiredFlags(cmd *cobra.Command) error {
+	if err := cmd.ValidateRequiredFlags(); err != nil {
+		return &UsageError{Cmd: cmd.CommandPath(), Err: err}
+	}
+	if err := cmd.ValidateFlagGroups(); err != nil {
+		return &UsageError{Cmd: cmd.CommandPath(), Err: err}
+	}
+	return nil
+}
@@ -0,0 +1,81 @@
+package cli
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestArgSpecs_Validate(t *testing.T) {
+	specs := argSpecs{
+		{Name: "action", Choices: []string{"start", "stop"}},
+		{Name: "count", Type: ArgInt, Optional: true},
+		{Name: "timeout", Type: ArgDuration, Optional: true},
+		{Name: "targets", Variadic: true, Max: 2},
+	}
+	if err := specs.check(); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name    string
+		args    []string
+		want    map[string]any
+		wantErr bool
+	}{
+		{
+			name: "required only",
+			args: []string{"start"},
+			want: map[string]any{"action": "start"},
+		},
+		{
+			name: "all typed",
+			args: []string{"stop", "3", "1s", "a", "b"},
+			want: map[string]any{"action": "stop", "count": 3, "timeout": time.Second, "targets": []any{"a", "b"}},
+		},
+		{name: "missing required", args: nil, wantErr: true},
+		{name: "invalid choice", args: []string{"restart"}, wantErr: true},
+		{name: "invalid int", args: []string{"start", "x"}, wantErr: true},
+		{name: "too many", args: []string{"start", "1", "1s", "a", "b", "c"}, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := specs.validate("app", tt.args)
+			if tt.wantErr {
+				var argsErr *ArgsError
+				if !errors.As(err, &argsErr) {
+					t.Fatalf("want *ArgsError, got %v", err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(got.values, tt.want) {
+				t.Errorf("got %v, want %v", got.values, tt.want)
+			}
+		})
+	}
+}
+
+func TestArgSpecs_Check(t *testing.T) {
+	tests := []struct {
+		name  string
+		specs argSpecs
+	}{
+		{name: "unnamed", specs: argSpecs{{}}},
+		{name: "variadic not last", specs: argSpecs{{Name: "a", Variadic: true}, {Name: "b"}}},
+		{name: "required after optional", specs: argSpecs{{Name: "a", Optional: true}, {Name: "b"}}},
+		{name: "max below min", specs: argSpecs{{Name: "a", Variadic: true, Min: 2, Max: 1}}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := tt.specs.check(); err == nil {
+				t.Error("want error, got nil")
+			}
+		})
+	}
+}
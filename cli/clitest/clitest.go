@@ -0,0 +1,175 @@
+// Package clitest 在进程内运行 cli.Commander 命令树，用于端到端测试
+package clitest
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/chhz0/goose/cli"
+)
+
+// UpdateGoldenEnv 设置为非空值时，Golden 会用实际输出覆盖 golden 文件
+const UpdateGoldenEnv = "UPDATE_GOLDEN"
+
+// Option 配置一次命令执行
+type Option func(*config)
+
+type config struct {
+	ctx     context.Context
+	args    []string
+	env     map[string]string
+	stdin   string
+	files   map[string]string
+	cliOpts []cli.Option
+}
+
+// Args 设置命令行参数，不包含程序名
+func Args(args ...string) Option {
+	return func(c *config) {
+		c.args = append(c.args, args...)
+	}
+}
+
+// Env 在执行期间设置环境变量，测试结束后恢复
+func Env(key, value string) Option {
+	return func(c *config) {
+		c.env[key] = value
+	}
+}
+
+// Stdin 设置标准输入的内容
+func Stdin(s string) Option {
+	return func(c *config) {
+		c.stdin = s
+	}
+}
+
+// File 在临时工作目录中写入文件，name 为相对路径，常用于提供配置文件
+func File(name, content string) Option {
+	return func(c *config) {
+		c.files[name] = content
+	}
+}
+
+// Context 设置执行使用的 context
+func Context(ctx context.Context) Option {
+	return func(c *config) {
+		c.ctx = ctx
+	}
+}
+
+// With 追加传递给 cli.NewCommand 的选项
+func With(opts ...cli.Option) Option {
+	return func(c *config) {
+		c.cliOpts = append(c.cliOpts, opts...)
+	}
+}
+
+// Result 一次命令执行的结果
+type Result struct {
+	Stdout string
+	// Stderr 包含 Run 输出的错误与用法提示
+	Stderr   string
+	Err      error
+	ExitCode int
+	// Dir 执行时的临时工作目录
+	Dir string
+}
+
+// Run 构建 root 命令树并在临时工作目录中以 cli.Exec 的 Run 执行
+// 由于会修改环境变量与工作目录，不能与 t.Parallel 一起使用
+func Run(t testing.TB, root cli.Commander, opts ...Option) *Result {
+	t.Helper()
+
+	c := &config{
+		ctx:   context.Background(),
+		args:  []string{},
+		env:   make(map[string]string),
+		files: make(map[string]string),
+	}
+	for _, opt := range opts {
+		opt(c)
+	}
+
+	dir := t.TempDir()
+	for name, content := range c.files {
+		path := filepath.Join(dir, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatalf("clitest: %v", err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+			t.Fatalf("clitest: %v", err)
+		}
+	}
+	t.Chdir(dir)
+	for k, v := range c.env {
+		t.Setenv(k, v)
+	}
+
+	var (
+		stdout, stderr bytes.Buffer
+		runErr         error
+	)
+	cliOpts := append([]cli.Option{
+		cli.WithArgs(c.args...),
+		cli.WithIOStreams(cli.IOStreams{
+			In:     strings.NewReader(c.stdin),
+			Out:    &stdout,
+			ErrOut: &stderr,
+		}),
+	}, c.cliOpts...)
+	// 通过 Run 执行，使 Stderr 包含与真实进程相同的错误输出，错误由 WithTeardown 记录
+	cliOpts = append(cliOpts, cli.WithTeardown(func(ctx context.Context, err error) error {
+		runErr = err
+		return nil
+	}))
+
+	exec, err := cli.NewCommand(root, cliOpts...)
+	if err != nil {
+		t.Fatalf("clitest: build command: %v", err)
+	}
+
+	code := exec.Run(c.ctx)
+	return &Result{
+		Stdout:   stdout.String(),
+		Stderr:   stderr.String(),
+		Err:      runErr,
+		ExitCode: code,
+		Dir:      dir,
+	}
+}
+
+// Golden 比较 got 与 golden 文件的内容，环境变量 UPDATE_GOLDEN 非空时更新 golden 文件
+// path 相对于测试所在包的目录，通常位于 testdata 下
+func Golden(t testing.TB, path string, got string) {
+	t.Helper()
+
+	if !filepath.IsAbs(path) {
+		path = filepath.Join(pkgDir, path)
+	}
+
+	if os.Getenv(UpdateGoldenEnv) != "" {
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatalf("clitest: %v", err)
+		}
+		if err := os.WriteFile(path, []byte(got), 0o644); err != nil {
+			t.Fatalf("clitest: %v", err)
+		}
+		return
+	}
+
+	want, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("clitest: read golden file: %v (run with %s=1 to create it)", err, UpdateGoldenEnv)
+	}
+	if got != string(want) {
+		t.Errorf("output does not match golden file %s\n--- got\n%s\n--- want\n%s", path, got, want)
+	}
+}
+
+// pkgDir 测试所在包的目录，Run 会切换工作目录，因此在包初始化时记录
+var pkgDir, _ = os.Getwd()
@@ -0,0 +1,108 @@
+package clitest_test
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/chhz0/goose/cli"
+	"github.com/chhz0/goose/cli/clitest"
+)
+
+type greetOptions struct {
+	Greeting string `mapstructure:"greeting" usage:"greeting word" default:"hello"`
+}
+
+func newRoot() cli.Commander {
+	opts := &greetOptions{}
+	return &cli.Command{
+		Use:   "greet",
+		Short: "Greet someone.",
+		Arguments: []cli.Arg{
+			{Name: "name", Usage: "who to greet", Optional: true},
+		},
+		FlagSet: cli.FlagsFromStruct(opts),
+		Config:  cli.NewConfig(opts, "greet", ".").WithEnvPrefix("GREET"),
+		Run: func(ctx context.Context, args []string) error {
+			streams := cli.IO(ctx)
+			name := cli.ArgsFrom(ctx).String("name")
+			if name == "" {
+				in, err := io.ReadAll(streams.In)
+				if err != nil {
+					return err
+				}
+				name = strings.TrimSpace(string(in))
+			}
+			if name == "" {
+				return &cli.ExitError{Code: 3, Err: fmt.Errorf("nobody to greet")}
+			}
+			_, err := fmt.Fprintf(streams.Out, "%s, %s\n", opts.Greeting, name)
+			return err
+		},
+		Commands: []cli.Commander{cli.VersionCommand()},
+	}
+}
+
+func TestRun(t *testing.T) {
+	tests := []struct {
+		name     string
+		opts     []clitest.Option
+		want     string
+		wantCode int
+		wantErr  string
+	}{
+		{name: "args", opts: []clitest.Option{clitest.Args("world")}, want: "hello, world\n"},
+		{name: "stdin", opts: []clitest.Option{clitest.Stdin("gopher\n")}, want: "hello, gopher\n"},
+		{
+			name: "config file",
+			opts: []clitest.Option{clitest.Args("world"), clitest.File("greet.yaml", "greeting: hi\n")},
+			want: "hi, world\n",
+		},
+		{
+			name: "env over file",
+			opts: []clitest.Option{
+				clitest.Args("world"),
+				clitest.File("greet.yaml", "greeting: hi\n"),
+				clitest.Env("GREET_GREETING", "hey"),
+			},
+			want: "hey, world\n",
+		},
+		{name: "exit code", wantCode: 3, wantErr: "Error: nobody to greet\n"},
+		{name: "usage error", opts: []clitest.Option{clitest.Args("a", "b")}, wantCode: cli.ExitUsage,
+			wantErr: "Error: invalid arguments for \"greet\": accepts at most 1 arg(s), received 2\n"},
+		{
+			name:     "unknown flag",
+			opts:     []clitest.Option{clitest.Args("--nope"), clitest.With(cli.WithUsageHints())},
+			wantCode: cli.ExitUsage,
+			wantErr:  "Error: unknown flag: --nope\nRun 'greet --help' for usage.\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			res := clitest.Run(t, newRoot(), tt.opts...)
+			if res.ExitCode != tt.wantCode {
+				t.Fatalf("exit code = %d, want %d (err: %v)", res.ExitCode, tt.wantCode, res.Err)
+			}
+			if res.Stdout != tt.want {
+				t.Errorf("stdout = %q, want %q", res.Stdout, tt.want)
+			}
+			if res.Stderr != tt.wantErr {
+				t.Errorf("stderr = %q, want %q", res.Stderr, tt.wantErr)
+			}
+			if (res.Err != nil) != (tt.wantCode != cli.ExitOK) {
+				t.Errorf("err = %v, want exit code %d", res.Err, tt.wantCode)
+			}
+		})
+	}
+}
+
+func TestRun_HelpGolden(t *testing.T) {
+	res := clitest.Run(t, newRoot(), clitest.Args("--help"))
+	if res.Err != nil {
+		t.Fatal(res.Err)
+	}
+	clitest.Golden(t, "testdata/greet-help.golden", res.Stdout)
+}
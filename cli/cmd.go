@@ -2,21 +2,86 @@ package cli
 
 import (
 	"context"
+	"errors"
+	"fmt"
+	"strings"
 
 	"github.com/spf13/cobra"
 )
 
 type Exec interface {
 	Execute(ctx context.Context) error
+	// Run 执行命令并输出错误，返回进程退出码，通常用法为 os.Exit(exec.Run(ctx))
+	Run(ctx context.Context) int
 }
 
 type executor struct {
-	cobra *cobra.Command
+	cobra    *cobra.Command
+	builders map[*cobra.Command]*commandBuilder
+	opts     *options
+
+	// shellCmd 为 WithShell 添加的 shell 子命令，inShell 防止嵌套进入交互模式
+	shellCmd *cobra.Command
+	inShell  bool
+	session  *session
+	// flagsPrepared 表示 flag 已可以在 shell 的两行之间恢复，参见 prepareFlags
+	flagsPrepared bool
 }
 
 // Execute implements Exec.
 func (e *executor) Execute(ctx context.Context) error {
-	return e.cobra.ExecuteContext(ctx)
+	cmd, err := e.execute(ctx)
+	if e.shellCmd != nil && cmd == e.shellCmd {
+		if err != nil {
+			return e.endSession(ctx, err)
+		}
+		return e.shell(ctx)
+	}
+	return err
+}
+
+func (e *executor) execute(ctx context.Context) (*cobra.Command, error) {
+	ctx, stop := e.signalContext(ctx)
+	defer stop()
+	ctx = e.ioContext(ctx)
+
+	cmd, err := e.cobra.ExecuteContextC(ctx)
+	tctx := ctx
+	if cmd != nil && cmd.Context() != nil {
+		tctx = cmd.Context()
+	}
+	if cb, ok := e.builders[cmd]; ok {
+		err = cb.teardown(tctx, err)
+	}
+	if e.opts.teardown != nil {
+		if terr := e.opts.teardown(tctx, err); terr != nil {
+			err = errors.Join(err, terr)
+		}
+	}
+	return cmd, err
+}
+
+// ioContext 返回携带命令标准流的 context，参见 IO
+func (e *executor) ioContext(ctx context.Context) context.Context {
+	return context.WithValue(ctx, ioKey, IOStreams{
+		In:     e.cobra.InOrStdin(),
+		Out:    e.cobra.OutOrStdout(),
+		ErrOut: e.cobra.ErrOrStderr(),
+	})
+}
+
+// Run implements Exec.
+func (e *executor) Run(ctx context.Context) int {
+	return e.exit(e.Execute(ctx))
+}
+
+// exit 输出错误并返回对应的退出码
+func (e *executor) exit(err error) int {
+	code := ExitCode(err)
+	if err != nil {
+		e.printError(e.cobra.ErrOrStderr(), err, code)
+	}
+	return code
 }
 
 // Commander
@@ -34,23 +99,149 @@ type Commander interface {
 	Cobra() *cobra.Command
 }
 
-type Command struct {
-	Use   string
-	Short string
-	Long  string
+// 以下为 Commander 的可选接口，Command 实现了全部可选接口，
+// 自定义的 Commander 按需实现，未实现时使用零值
+
+// DescribedCommander 提供示例、别名、分组、隐藏与废弃信息
+type DescribedCommander interface {
+	Examples() string
+	CommandAliases() []string
+	Group() string
+	CommandGroups() []*cobra.Group
+	IsHidden() bool
+	DeprecatedMsg() string
+}
 
-	Inits  func() []func()
-	PreRun func(ctx context.Context, args []string) error
-	Run    func(ctx context.Context, args []string) error
+// HookedCommander 提供 PreFunc/RunFunc 之外的生命周期钩子，参见 lifecycle.go
+type HookedCommander interface {
+	PersistentPreFunc(ctx context.Context, args []string) error
+	PostFunc(ctx context.Context, args []string) error
+	PersistentPostFunc(ctx context.Context, args []string) error
+	TeardownFunc(ctx context.Context, err error) error
+}
 
-	Args     *cobra.PositionalArgs
-	FlagSet  Flager
-	Commands []Commander
+// ArgsCommander 提供位置参数的校验与声明
+type ArgsCommander interface {
+	ArgsValidator() cobra.PositionalArgs
+	ArgSpecs() []Arg
+}
+
+// CompletionCommander 提供位置参数与 flag 值的动态补全
+type CompletionCommander interface {
+	ArgsCompletion() CompleteFunc
+	FlagCompletions() map[string]CompleteFunc
+}
+
+// ConfigCommander 提供命令的配置来源
+type ConfigCommander interface {
+	Configer() Configer
+}
+
+// OptionsCommander 提供在 PreRun 之前 Complete/Validate 的选项结构体
+type OptionsCommander interface {
+	Opts() any
+}
+
+// LogFlagsCommander 决定是否注册 --log-* flag
+type LogFlagsCommander interface {
+	LogFlags() bool
+}
+
+// EnvCommander 提供 flag 绑定的环境变量前缀
+type EnvCommander interface {
+	FlagEnvPrefix() string
+}
+
+// DeprecatedFlagsCommander 提供废弃的 flag 及其提示信息
+type DeprecatedFlagsCommander interface {
+	FlagDeprecations() map[string]string
+}
+
+// InteractiveCommander 提供需要交互输入的 flag 与运行前的确认信息
+type InteractiveCommander interface {
+	PromptableFlags() []string
+	ConfirmMsg() string
+}
+
+var (
+	_ DescribedCommander       = (*Command)(nil)
+	_ HookedCommander          = (*Command)(nil)
+	_ ArgsCommander            = (*Command)(nil)
+	_ CompletionCommander      = (*Command)(nil)
+	_ ConfigCommander          = (*Command)(nil)
+	_ OptionsCommander         = (*Command)(nil)
+	_ LogFlagsCommander        = (*Command)(nil)
+	_ EnvCommander             = (*Command)(nil)
+	_ DeprecatedFlagsCommander = (*Command)(nil)
+	_ InteractiveCommander     = (*Command)(nil)
+)
+
+// optional 返回 c 实现的可选接口 T，未实现时返回零值实现
+func optional[T any](c Commander) T {
+	if t, ok := c.(T); ok {
+		return t
+	}
+	return any(&Command{}).(T)
+}
+
+type Command struct {
+	Use     string
+	Short   string
+	Long    string
+	Example string
+	Aliases []string
+
+	// GroupID 指定命令在父命令帮助信息中所属的分组，分组由父命令的 Groups 定义
+	GroupID string
+	// Groups 定义子命令的分组，帮助信息按定义顺序显示，未分组的子命令显示在 Additional Commands 中
+	Groups []*cobra.Group
+	Hidden bool
+	// Deprecated 不为空时命令被标记为废弃，执行时输出该信息
+	Deprecated string
+
+	// Inits 仅在该命令被执行时运行，早于 PersistentPreRun
+	Inits func() []func()
+	// PersistentPreRun 从根命令到被执行命令依次运行
+	PersistentPreRun func(ctx context.Context, args []string) error
+	PreRun           func(ctx context.Context, args []string) error
+	Run              func(ctx context.Context, args []string) error
+	PostRun          func(ctx context.Context, args []string) error
+	// PersistentPostRun 从被执行命令到根命令依次运行
+	PersistentPostRun func(ctx context.Context, args []string) error
+	// Teardown 从被执行命令到根命令依次运行，无论执行是否出错，err 为执行结果
+	Teardown func(ctx context.Context, err error) error
+
+	Args          *cobra.PositionalArgs
+	Arguments     []Arg
+	CompleteArgs  CompleteFunc
+	CompleteFlags map[string]CompleteFunc
+
+	FlagSet Flager
+	// DeprecatedFlags 以 flag 名称为键标记废弃的 flag，值为使用时输出的提示信息
+	DeprecatedFlags map[string]string
+	// PromptFlags 为必需但可交互输入的 flag，未指定且标准输入为终端时询问用户，否则报错
+	PromptFlags []string
+	// Confirm 不为空时命令在运行前需要用户确认，常用于破坏性操作，--yes 跳过确认
+	Confirm string
+	// EnvPrefix 不为空时，命令的 flag 也可以通过 PREFIX_FLAG_NAME 形式的环境变量指定，
+	// 子命令继承为 PREFIX_SUBCMD_FLAG_NAME；优先级: flag > env > default
+	EnvPrefix string
+	// EnableLogFlags 注册 --log-level/--log-format/--log-file/--log-rotate persistent flag，
+	// 并在 Inits 之前据此替换 log 包的默认 logger
+	EnableLogFlags bool
+	Config         Configer
+	Options        any // 选项结构体，实现 Completer/Validator 时在 PreRun 之前调用
+	Commands       []Commander
 
 	cobra *cobra.Command
 }
 
-func NewCommand(rcmd Commander) (Exec, error) {
+func NewCommand(rcmd Commander, opts ...Option) (Exec, error) {
+	o := defaultOptions()
+	for _, opt := range opts {
+		opt(o)
+	}
+
 	rbuilder := &commandBuilder{
 		commander: rcmd,
 	}
@@ -59,6 +250,7 @@ func NewCommand(rcmd Commander) (Exec, error) {
 	addCmdBuilder = func(cb *commandBuilder, cmder Commander) {
 		cb2 := &commandBuilder{
 			commander: cmder,
+			parent:    cb,
 		}
 		cb.subCmdBuilders = append(cb.subCmdBuilders, cb2)
 		for _, c := range cmder.Commanders() {
@@ -69,12 +261,76 @@ func NewCommand(rcmd Commander) (Exec, error) {
 	for _, cmder := range rcmd.Commanders() {
 		addCmdBuilder(rbuilder, cmder)
 	}
+	if !hasCommander(rcmd, completionCmdName) {
+		addCmdBuilder(rbuilder, completionCommand(commandName(rcmd.Usage())))
+	}
+	if o.docs && !hasCommander(rcmd, docsCmdName) {
+		addCmdBuilder(rbuilder, docsCommand())
+	}
+	var shellCmder Commander
+	if o.shell && !hasCommander(rcmd, shellCmdName) {
+		shellCmder = shellCommand()
+		addCmdBuilder(rbuilder, shellCmder)
+	}
 
+	rbuilder.walk(func(cb *commandBuilder) {
+		cb.detached = o.detached
+	})
 	if err := rbuilder.build(); err != nil {
 		return nil, err
 	}
 
-	return &executor{rbuilder.cobra}, nil
+	rbuilder.cobra.CompletionOptions.DisableDefaultCmd = true
+	if rbuilder.needsPrompt() {
+		if err := addPromptFlags(rbuilder.cobra); err != nil {
+			return nil, err
+		}
+	}
+	if o.plugins {
+		addPlugins(rbuilder.cobra, o.pluginDirs)
+	}
+	o.apply(rbuilder.cobra)
+	rbuilder.cobra.SetUsageTemplate(usageTemplate)
+	rbuilder.cobra.SetFlagErrorFunc(func(cmd *cobra.Command, err error) error {
+		return &UsageError{Cmd: cmd.CommandPath(), Err: err}
+	})
+
+	exec := &executor{
+		cobra:    rbuilder.cobra,
+		builders: make(map[*cobra.Command]*commandBuilder),
+		opts:     o,
+		session:  &session{},
+	}
+	if shellCmder != nil {
+		exec.shellCmd = shellCmder.Cobra()
+		exec.session.shellCmd = exec.shellCmd
+	}
+	rbuilder.walk(func(cb *commandBuilder) {
+		exec.builders[cb.cobra] = cb
+		cb.prompter = o.prompter
+		cb.session = exec.session
+	})
+	if o.shell {
+		if err := exec.prepareFlags(); err != nil {
+			return nil, err
+		}
+	}
+
+	return exec, nil
+}
+
+func hasCommander(cmder Commander, name string) bool {
+	for _, c := range cmder.Commanders() {
+		if commandName(c.Usage()) == name {
+			return true
+		}
+	}
+	return false
+}
+
+func commandName(use string) string {
+	name, _, _ := strings.Cut(use, " ")
+	return name
 }
 
 func (c *Command) Usage() string {
@@ -89,12 +345,45 @@ func (c *Command) LongDesc() string {
 	return c.Long
 }
 
+func (c *Command) Examples() string {
+	return c.Example
+}
+
+func (c *Command) CommandAliases() []string {
+	return c.Aliases
+}
+
+func (c *Command) Group() string {
+	return c.GroupID
+}
+
+func (c *Command) CommandGroups() []*cobra.Group {
+	return c.Groups
+}
+
+func (c *Command) IsHidden() bool {
+	return c.Hidden
+}
+
+func (c *Command) DeprecatedMsg() string {
+	return c.Deprecated
+}
+
 func (c *Command) InitFunc() {
 	if c.Inits != nil {
-		cobra.OnInitialize(c.Inits()...)
+		for _, fn := range c.Inits() {
+			fn()
+		}
 	}
 }
 
+func (c *Command) PersistentPreFunc(ctx context.Context, args []string) error {
+	if c.PersistentPreRun != nil {
+		return c.PersistentPreRun(ctx, args)
+	}
+	return nil
+}
+
 func (c *Command) PreFunc(ctx context.Context, args []string) error {
 	if c.PreRun != nil {
 		return c.PreRun(ctx, args)
@@ -109,6 +398,46 @@ func (c *Command) RunFunc(ctx context.Context, args []string) error {
 	return nil
 }
 
+func (c *Command) PostFunc(ctx context.Context, args []string) error {
+	if c.PostRun != nil {
+		return c.PostRun(ctx, args)
+	}
+	return nil
+}
+
+func (c *Command) PersistentPostFunc(ctx context.Context, args []string) error {
+	if c.PersistentPostRun != nil {
+		return c.PersistentPostRun(ctx, args)
+	}
+	return nil
+}
+
+func (c *Command) TeardownFunc(ctx context.Context, err error) error {
+	if c.Teardown != nil {
+		return c.Teardown(ctx, err)
+	}
+	return nil
+}
+
+func (c *Command) ArgsValidator() cobra.PositionalArgs {
+	if c.Args != nil {
+		return *c.Args
+	}
+	return nil
+}
+
+func (c *Command) ArgSpecs() []Arg {
+	return c.Arguments
+}
+
+func (c *Command) ArgsCompletion() CompleteFunc {
+	return c.CompleteArgs
+}
+
+func (c *Command) FlagCompletions() map[string]CompleteFunc {
+	return c.CompleteFlags
+}
+
 func (c *Command) Flags() Flager {
 	if c.FlagSet != nil {
 		return c.FlagSet
@@ -116,6 +445,34 @@ func (c *Command) Flags() Flager {
 	return &FlagSet{}
 }
 
+func (c *Command) FlagDeprecations() map[string]string {
+	return c.DeprecatedFlags
+}
+
+func (c *Command) PromptableFlags() []string {
+	return c.PromptFlags
+}
+
+func (c *Command) ConfirmMsg() string {
+	return c.Confirm
+}
+
+func (c *Command) FlagEnvPrefix() string {
+	return c.EnvPrefix
+}
+
+func (c *Command) LogFlags() bool {
+	return c.EnableLogFlags
+}
+
+func (c *Command) Configer() Configer {
+	return c.Config
+}
+
+func (c *Command) Opts() any {
+	return c.Options
+}
+
 func (c *Command) Commanders() []Commander {
 	return c.Commands
 }
@@ -127,35 +484,107 @@ func (c *Command) Cobra() *cobra.Command {
 type commandBuilder struct {
 	cobra     *cobra.Command
 	commander Commander
+	logOpts   *logOptions
 
+	parent         *commandBuilder
 	subCmdBuilders []*commandBuilder
+
+	prompter Prompter
+	session  *session
+	detached bool
 }
 
 func (cb *commandBuilder) build() error {
+	specs := argSpecs(optional[ArgsCommander](cb.commander).ArgSpecs())
+	if err := specs.check(); err != nil {
+		return fmt.Errorf("command %q: %w", cb.commander.Usage(), err)
+	}
+
+	use := cb.commander.Usage()
+	if len(specs) > 0 && !strings.Contains(use, " ") {
+		use += " " + specs.synopsis()
+	}
+
+	desc := optional[DescribedCommander](cb.commander)
 	cb.cobra = &cobra.Command{
-		Use:   cb.commander.Usage(),
-		Short: cb.commander.ShortDesc(),
-		Long:  cb.commander.LongDesc(),
-		PreRunE: func(cmd *cobra.Command, args []string) error {
-			return cb.commander.PreFunc(cmd.Context(), args)
-		},
-		RunE: func(cmd *cobra.Command, args []string) error {
-			return cb.commander.RunFunc(cmd.Context(), args)
-		},
+		Use:                        use,
+		Short:                      cb.commander.ShortDesc(),
+		Long:                       cb.commander.LongDesc(),
+		Example:                    desc.Examples(),
+		Aliases:                    desc.CommandAliases(),
+		GroupID:                    desc.Group(),
+		Hidden:                     desc.IsHidden(),
+		Deprecated:                 desc.DeprecatedMsg(),
+		PersistentPreRunE:          cb.persistentPreRun,
+		PreRunE:                    cb.preRun,
+		RunE:                       cb.run,
+		PostRunE:                   cb.postRun,
+		PersistentPostRunE:         cb.persistentPostRun,
 		SilenceErrors:              true,
 		SilenceUsage:               true,
+		Args:                       cb.positionalArgs(),
 		SuggestionsMinimumDistance: 2,
 	}
+	if len(specs) > 0 {
+		cb.cobra.Annotations = map[string]string{argsAnnotation: specs.usages()}
+	}
+	if c, ok := cb.commander.(*Command); ok && !cb.detached {
+		c.cobra = cb.cobra
+	}
 
-	cb.commander.InitFunc()
 	cb.commander.Flags().ApplyFlags(cb.cobra)
+	if optional[LogFlagsCommander](cb.commander).LogFlags() {
+		cb.logOpts = &logOptions{}
+		cb.logOpts.addFlags(cb.cobra)
+	}
+	if cfgr := optional[ConfigCommander](cb.commander).Configer(); cfgr != nil && cb.cobra.PersistentFlags().Lookup(configFlagName) == nil {
+		cb.cobra.PersistentFlags().String(configFlagName, "", "path to the config file")
+	}
+	cb.bindFlagEnv(cb.cobra)
+	if err := cb.deprecateFlags(); err != nil {
+		return err
+	}
+
+	comp := optional[CompletionCommander](cb.commander)
+	if fn := comp.ArgsCompletion(); fn != nil {
+		cb.cobra.ValidArgsFunction = fn.cobraFunc()
+	}
+	for name, fn := range comp.FlagCompletions() {
+		if err := cb.cobra.RegisterFlagCompletionFunc(name, fn.cobraFunc()); err != nil {
+			return err
+		}
+	}
+	if err := registerValueCompletions(cb.cobra); err != nil {
+		return err
+	}
 
+	cb.cobra.AddGroup(desc.CommandGroups()...)
 	for _, sub := range cb.subCmdBuilders {
 		if err := sub.build(); err != nil {
 			return err
 		}
+		if id := sub.cobra.GroupID; id != "" && !cb.cobra.ContainsGroup(id) {
+			return fmt.Errorf("command %q: group %q is not defined by its parent", sub.cobra.Name(), id)
+		}
 		cb.cobra.AddCommand(sub.cobra)
 	}
 
 	return nil
 }
+
+// deprecateFlags 将 DeprecatedFlags 中的 flag 标记为废弃，flag 必须由该命令定义
+func (cb *commandBuilder) deprecateFlags() error {
+	for name, msg := range optional[DeprecatedFlagsCommander](cb.commander).FlagDeprecations() {
+		if msg == "" {
+			return fmt.Errorf("command %q: deprecation message for flag %q is empty", cb.cobra.Name(), name)
+		}
+		fs := cb.cobra.Flags()
+		if fs.Lookup(name) == nil {
+			fs = cb.cobra.PersistentFlags()
+		}
+		if err := fs.MarkDeprecated(name, msg); err != nil {
+			return fmt.Errorf("command %q: %w", cb.cobra.Name(), err)
+		}
+	}
+	return nil
+}
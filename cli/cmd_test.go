@@ -0,0 +1,96 @@
+package cli
+
+import (
+	"bytes"
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+)
+
+func newGroupedRoot(ran *string) Commander {
+	run := func(name string) func(ctx context.Context, args []string) error {
+		return func(ctx context.Context, args []string) error {
+			*ran = name
+			return nil
+		}
+	}
+	return &Command{
+		Use: "app",
+		Groups: []*cobra.Group{
+			{ID: "manage", Title: "Management Commands:"},
+			{ID: "debug", Title: "Debugging Commands:"},
+		},
+		Commands: []Commander{
+			&Command{Use: "trace", Short: "Trace requests.", GroupID: "debug", Run: run("trace")},
+			&Command{Use: "create", Short: "Create a resource.", GroupID: "manage", Aliases: []string{"new", "add"},
+				Example: "  app create web", Run: run("create")},
+			&Command{Use: "secret", Short: "Hidden command.", Hidden: true, Run: run("secret")},
+			&Command{Use: "old", Short: "Old command.", Deprecated: `use "create" instead`, Run: run("old")},
+			&Command{Use: "serve", Short: "Serve requests.", Run: run("serve"),
+				FlagSet: &FlagSet{Local: func(pfs *pflag.FlagSet) {
+					pfs.Int("port", 0, "")
+				}},
+				DeprecatedFlags: map[string]string{"port": "use --listen instead"},
+			},
+		},
+	}
+}
+
+func TestCommand_Metadata(t *testing.T) {
+	tests := []struct {
+		name    string
+		args    []string
+		wantRan string
+		want    []string
+	}{
+		{
+			name: "grouped help",
+			args: []string{"--help"},
+			want: []string{
+				"Management Commands:\n  create      Create a resource.\n\nDebugging Commands:\n  trace       Trace requests.\n\nAdditional Commands:\n",
+			},
+		},
+		{name: "alias", args: []string{"new"}, wantRan: "create"},
+		{name: "example", args: []string{"create", "--help"}, want: []string{"Aliases:\n  create, new, add", "Examples:\n  app create web"}},
+		{name: "hidden", args: []string{"secret"}, wantRan: "secret"},
+		{name: "deprecated", args: []string{"old"}, wantRan: "old", want: []string{`Command "old" is deprecated, use "create" instead`}},
+		{name: "deprecated flag", args: []string{"serve", "--port", "1"}, wantRan: "serve", want: []string{"Flag --port has been deprecated, use --listen instead"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var ran string
+			var out bytes.Buffer
+			exec, err := NewCommand(newGroupedRoot(&ran), WithArgs(tt.args...), WithIOStreams(IOStreams{Out: &out, ErrOut: &out}))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if err := exec.Execute(context.Background()); err != nil {
+				t.Fatal(err)
+			}
+			if ran != tt.wantRan {
+				t.Errorf("ran %q, want %q", ran, tt.wantRan)
+			}
+			for _, want := range tt.want {
+				if !strings.Contains(out.String(), want) {
+					t.Errorf("output %q does not contain %q", out.String(), want)
+				}
+			}
+			if strings.Contains(out.String(), "Hidden command.") {
+				t.Errorf("output lists hidden command: %q", out.String())
+			}
+		})
+	}
+}
+
+func TestCommand_UndefinedGroup(t *testing.T) {
+	_, err := NewCommand(&Command{
+		Use:      "app",
+		Commands: []Commander{&Command{Use: "sub", GroupID: "missing"}},
+	})
+	if err == nil || !strings.Contains(err.Error(), `group "missing" is not defined`) {
+		t.Errorf("NewCommand() error = %v, want undefined group", err)
+	}
+}
@@ -0,0 +1,65 @@
+package cli
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/spf13/cobra"
+)
+
+const completionCmdName = "completion"
+
+// CompleteFunc 为位置参数或 flag 值提供动态补全
+// args 为已输入的位置参数，toComplete 为当前正在输入的内容
+type CompleteFunc func(ctx context.Context, args []string, toComplete string) ([]string, cobra.ShellCompDirective)
+
+func (fn CompleteFunc) cobraFunc() cobra.CompletionFunc {
+	return func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+		return fn(cmd.Context(), args, toComplete)
+	}
+}
+
+var completionShells = []string{"bash", "zsh", "fish", "powershell"}
+
+// completionCommand 返回 completion 子命令，由 NewCommand 自动添加到根命令，root 为根命令的名称
+func completionCommand(root string) Commander {
+	cmd := &Command{
+		Use:   completionCmdName,
+		Short: "Generate the autocompletion script for the specified shell.",
+		Long: fmt.Sprintf(`Generate the autocompletion script for the specified shell.
+
+To load completions in the current shell session:
+
+  bash:       source <(%[1]s completion bash)
+  zsh:        source <(%[1]s completion zsh)
+  fish:       %[1]s completion fish | source
+  powershell: %[1]s completion powershell | Out-String | Invoke-Expression
+
+Write the output to the shell's completion directory to load it for every new session.`, root),
+		Arguments: []Arg{
+			{Name: "shell", Usage: "shell to generate the script for", Choices: completionShells},
+		},
+		CompleteArgs: func(ctx context.Context, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+			if len(args) != 0 {
+				return nil, cobra.ShellCompDirectiveNoFileComp
+			}
+			return completionShells, cobra.ShellCompDirectiveNoFileComp
+		},
+	}
+	cmd.Run = func(ctx context.Context, args []string) error {
+		root := cmd.Cobra().Root()
+		out := cmd.Cobra().OutOrStdout()
+		switch ArgsFrom(ctx).String("shell") {
+		case "bash":
+			return root.GenBashCompletionV2(out, true)
+		case "zsh":
+			return root.GenZshCompletion(out)
+		case "fish":
+			return root.GenFishCompletion(out, true)
+		default:
+			return root.GenPowerShellCompletionWithDesc(out)
+		}
+	}
+
+	return cmd
+}
@@ -0,0 +1,112 @@
+package cli
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+)
+
+func newCompletionRoot() Commander {
+	return &Command{
+		Use: "app",
+		Commands: []Commander{
+			&Command{
+				Use: "deploy",
+				CompleteArgs: func(ctx context.Context, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+					return []string{"staging", "production"}, cobra.ShellCompDirectiveNoFileComp
+				},
+				CompleteFlags: map[string]CompleteFunc{
+					"region": func(ctx context.Context, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+						return []string{"us-east", "eu-west"}, cobra.ShellCompDirectiveNoFileComp
+					},
+				},
+				FlagSet: &FlagSet{Local: func(pfs *pflag.FlagSet) {
+					pfs.String("region", "", "target region")
+				}},
+				Run: func(ctx context.Context, args []string) error { return nil },
+			},
+		},
+	}
+}
+
+func TestCompletionCommand(t *testing.T) {
+	tests := []struct {
+		name     string
+		args     []string
+		want     string
+		wantCode int
+	}{
+		{name: "bash", args: []string{"completion", "bash"}, want: "bash completion V2 for app"},
+		{name: "zsh", args: []string{"completion", "zsh"}, want: "#compdef app"},
+		{name: "fish", args: []string{"completion", "fish"}, want: "fish completion for app"},
+		{name: "powershell", args: []string{"completion", "powershell"}, want: "powershell completion for app"},
+		{name: "help", args: []string{"completion", "--help"}, want: "source <(app completion bash)"},
+		{name: "missing shell", args: []string{"completion"}, wantCode: ExitUsage},
+		{name: "unknown shell", args: []string{"completion", "tcsh"}, wantCode: ExitUsage},
+		{name: "args", args: []string{"__complete", "deploy", ""}, want: "staging\nproduction\n"},
+		{name: "flag", args: []string{"__complete", "deploy", "--region", ""}, want: "us-east\neu-west\n"},
+		{name: "shells", args: []string{"__complete", "completion", ""}, want: "bash\nzsh\nfish\npowershell\n"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var out bytes.Buffer
+			exec, err := NewCommand(newCompletionRoot(), WithArgs(tt.args...), WithIOStreams(IOStreams{Out: &out, ErrOut: &out}))
+			if err != nil {
+				t.Fatal(err)
+			}
+			err = exec.Execute(context.Background())
+			if code := ExitCode(err); code != tt.wantCode {
+				t.Fatalf("exit code = %d, want %d, err = %v", code, tt.wantCode, err)
+			}
+			if tt.wantCode == ExitUsage {
+				var argsErr *ArgsError
+				if !errors.As(err, &argsErr) {
+					t.Errorf("got %T, want *ArgsError", err)
+				}
+			}
+			if !strings.Contains(out.String(), tt.want) {
+				t.Errorf("output does not contain %q:\n%s", tt.want, out.String())
+			}
+		})
+	}
+}
+
+// minimalCommander 只实现 Commander 本身，不实现任何可选接口
+type minimalCommander struct {
+	use   string
+	ran   bool
+	cobra *cobra.Command
+}
+
+func (c *minimalCommander) Usage() string                                    { return c.use }
+func (c *minimalCommander) ShortDesc() string                                { return "" }
+func (c *minimalCommander) LongDesc() string                                 { return "" }
+func (c *minimalCommander) InitFunc()                                        {}
+func (c *minimalCommander) PreFunc(ctx context.Context, args []string) error { return nil }
+func (c *minimalCommander) RunFunc(ctx context.Context, args []string) error {
+	c.ran = true
+	return nil
+}
+func (c *minimalCommander) Flags() Flager           { return &FlagSet{} }
+func (c *minimalCommander) Commanders() []Commander { return nil }
+func (c *minimalCommander) Cobra() *cobra.Command   { return c.cobra }
+
+func TestNewCommand_MinimalCommander(t *testing.T) {
+	sub := &minimalCommander{use: "sub"}
+	exec, err := NewCommand(&Command{Use: "app", Commands: []Commander{sub}},
+		WithArgs("sub"), WithIOStreams(IOStreams{Out: &bytes.Buffer{}, ErrOut: &bytes.Buffer{}}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := exec.Execute(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if !sub.ran {
+		t.Error("RunFunc was not called")
+	}
+}
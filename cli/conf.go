@@ -1,18 +1,100 @@
 package cli
 
-import "github.com/spf13/viper"
+import (
+	confv2 "github.com/chhz0/goose/conf/v2"
+	"github.com/spf13/pflag"
+)
 
+const configFlagName = "config"
+
+// Configer 描述命令的配置来源，在 PreRun 之前由 commandBuilder 加载，
+// 同样作用于该命令的全部子命令
+// 优先级: flag > env > file > default
 type Configer interface {
 	ConfigFile() string
 	ConfigPath() []string
-	V() *viper.Viper
+	EnvPrefix() string
+
+	// Load 加载配置并反序列化到目标结构体，file 不为空时直接读取该文件而不再搜索 ConfigPath
+	Load(file string, fss ...*pflag.FlagSet) error
+	// Config 返回最近一次 Load 得到的配置
+	Config() *confv2.Config
 }
 
 type config struct {
-	ptr any
+	name      string
+	typ       string
+	paths     []string
+	envPrefix string
+	ptr       any
+
+	cfg *confv2.Config
+}
+
+// NewConfig 创建一个配置目标，ptr 必须是指向选项结构体的指针
+// name 为不带扩展名的配置文件名，paths 为搜索路径
+func NewConfig(ptr any, name string, paths ...string) *config {
+	return &config{
+		name:  name,
+		paths: paths,
+		ptr:   ptr,
+	}
 }
 
-func NewConfig() *config {
+// WithType 指定配置文件类型，默认根据扩展名推断
+func (c *config) WithType(typ string) *config {
+	c.typ = typ
+	return c
+}
+
+// WithEnvPrefix 指定环境变量前缀
+func (c *config) WithEnvPrefix(prefix string) *config {
+	c.envPrefix = prefix
+	return c
+}
+
+func (c *config) ConfigFile() string {
+	return c.name
+}
+
+func (c *config) ConfigPath() []string {
+	return c.paths
+}
+
+func (c *config) EnvPrefix() string {
+	return c.envPrefix
+}
+
+func (c *config) Load(file string, fss ...*pflag.FlagSet) error {
+	bound := make([]*pflag.FlagSet, 0, len(fss))
+	for _, fs := range fss {
+		bound = append(bound, configFlags(fs))
+	}
+	b := confv2.Init().
+		WithEnvPrefix(c.envPrefix).
+		WithFlags(bound...)
+
+	switch {
+	case file != "":
+		b.WithConfigFilePath(file, c.typ)
+	case c.name != "":
+		b.WithConfigFile(c.name, c.typ, c.paths...).WithConfigOptional(true)
+	}
+
+	cfg, err := b.Loading()
+	if err != nil {
+		return err
+	}
+	if c.ptr != nil {
+		if err := cfg.Unmarshal(c.ptr); err != nil {
+			return err
+		}
+	}
+
+	c.cfg = cfg
+	return nil
+}
 
-	return &config{}
+func (c *config) Config() *confv2.Config {
+	return c.cfg
 }
@@ -0,0 +1,125 @@
+package cli
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/spf13/pflag"
+)
+
+type confTestOptions struct {
+	Name  string `mapstructure:"name"`
+	Port  int    `mapstructure:"port"`
+	Level string `mapstructure:"level"`
+	Mode  string `mapstructure:"mode"`
+}
+
+func TestCommand_Config(t *testing.T) {
+	dir := t.TempDir()
+	file := filepath.Join(dir, "app.yaml")
+	content := "name: file\nport: 1\nlevel: file\n"
+	if err := os.WriteFile(file, []byte(content), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("APP_PORT", "2")
+	t.Setenv("APP_LEVEL", "env")
+
+	tests := []struct {
+		name string
+		args []string
+		want confTestOptions
+	}{
+		{
+			name: "search paths",
+			args: []string{"--name", "flag"},
+			want: confTestOptions{Name: "flag", Port: 2, Level: "env", Mode: "default"},
+		},
+		{
+			name: "explicit config flag",
+			args: []string{"--config", file, "--level", "flag"},
+			want: confTestOptions{Name: "file", Port: 2, Level: "flag", Mode: "default"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			opts := &confTestOptions{}
+			var got confTestOptions
+			exec, err := NewCommand(&Command{
+				Use: "app",
+				FlagSet: &FlagSet{
+					Local: func(pfs *pflag.FlagSet) {
+						pfs.StringVar(&opts.Name, "name", "", "")
+						pfs.StringVar(&opts.Level, "level", "", "")
+						pfs.StringVar(&opts.Mode, "mode", "default", "")
+					},
+				},
+				Config: NewConfig(opts, "app", dir).WithEnvPrefix("APP"),
+				Run: func(ctx context.Context, args []string) error {
+					got = *opts
+					return nil
+				},
+			})
+			if err != nil {
+				t.Fatal(err)
+			}
+			exec.(*executor).cobra.SetArgs(tt.args)
+			if err := exec.Execute(context.Background()); err != nil {
+				t.Fatal(err)
+			}
+			if got != tt.want {
+				t.Errorf("got %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCommand_ConfigSubcommand(t *testing.T) {
+	dir := t.TempDir()
+	file := filepath.Join(dir, "app.yaml")
+	if err := os.WriteFile(file, []byte("name: file\nport: 1\nlevel: file\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name string
+		args []string
+		want confTestOptions
+	}{
+		{name: "search paths", args: []string{"sub"}, want: confTestOptions{Name: "file", Port: 1, Level: "file"}},
+		{name: "explicit config flag", args: []string{"sub", "--config", file, "--level", "flag"},
+			want: confTestOptions{Name: "file", Port: 1, Level: "flag"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			opts := &confTestOptions{}
+			var got confTestOptions
+			exec, err := NewCommand(&Command{
+				Use: "app",
+				FlagSet: &FlagSet{
+					Persistent: func(pfs *pflag.FlagSet) {
+						pfs.StringVar(&opts.Level, "level", "", "")
+					},
+				},
+				Config: NewConfig(opts, "app", dir),
+				Commands: []Commander{
+					&Command{Use: "sub", Run: func(ctx context.Context, args []string) error {
+						got = *opts
+						return nil
+					}},
+				},
+			}, WithArgs(tt.args...))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if err := exec.Execute(context.Background()); err != nil {
+				t.Fatal(err)
+			}
+			if got != tt.want {
+				t.Errorf("got %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
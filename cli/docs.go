@@ -0,0 +1,107 @@
+package cli
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/cobra/doc"
+	"github.com/spf13/pflag"
+)
+
+const docsCmdName = "docs"
+
+// DocFormat 文档格式
+type DocFormat string
+
+const (
+	DocMan      DocFormat = "man"
+	DocMarkdown DocFormat = "markdown"
+	DocReST     DocFormat = "rst"
+)
+
+// GenDocs 为 root 的整个命令树生成文档，每个命令一个文件，写入 dir 目录。
+// 文档包含用法、位置参数、示例、flag 及其环境变量与子命令列表，隐藏的命令不会生成文档；
+// 文档由独立构建的命令树生成，不影响 root 用于 NewCommand 时 Cobra 的返回值
+func GenDocs(root Commander, format DocFormat, dir string) error {
+	exec, err := NewCommand(root, withDetached())
+	if err != nil {
+		return err
+	}
+	return genDocs(exec.(*executor).cobra, format, dir)
+}
+
+func genDocs(root *cobra.Command, format DocFormat, dir string) error {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return err
+	}
+
+	// 文档生成器不使用帮助模板，将位置参数的说明临时追加到 Long 中
+	restore := appendArgsToLong(root)
+	defer restore()
+	autoGenTag := root.DisableAutoGenTag
+	root.DisableAutoGenTag = true
+	defer func() { root.DisableAutoGenTag = autoGenTag }()
+
+	switch format {
+	case DocMan:
+		return doc.GenManTree(root, &doc.GenManHeader{
+			Title:   strings.ToUpper(root.Name()),
+			Section: "1",
+		}, dir)
+	case DocMarkdown:
+		return doc.GenMarkdownTree(root, dir)
+	case DocReST:
+		return doc.GenReSTTree(root, dir)
+	default:
+		return fmt.Errorf("unsupported doc format %q, must be one of: man|markdown|rst", format)
+	}
+}
+
+func appendArgsToLong(root *cobra.Command) func() {
+	longs := make(map[*cobra.Command]string)
+	var visit func(c *cobra.Command)
+	visit = func(c *cobra.Command) {
+		if usages, ok := c.Annotations[argsAnnotation]; ok {
+			longs[c] = c.Long
+			long := c.Long
+			if long == "" {
+				long = c.Short
+			}
+			c.Long = strings.TrimSpace(long) + "\n\nArguments:\n" + usages
+		}
+		for _, sub := range c.Commands() {
+			visit(sub)
+		}
+	}
+	visit(root)
+
+	return func() {
+		for c, long := range longs {
+			c.Long = long
+		}
+	}
+}
+
+// docsCommand 返回隐藏的 docs 子命令，由 WithDocs 添加到根命令
+func docsCommand() Commander {
+	format := string(DocMarkdown)
+	dir := "docs"
+	cmd := &Command{
+		Use:    docsCmdName,
+		Short:  "Generate documentation for all commands.",
+		Hidden: true,
+		FlagSet: &FlagSet{
+			Local: func(pfs *pflag.FlagSet) {
+				pfs.Var(NewEnumValue(&format, string(DocMan), string(DocMarkdown), string(DocReST)), "format", "documentation format")
+				pfs.StringVar(&dir, "dir", dir, "output directory")
+			},
+		},
+	}
+	cmd.Run = func(ctx context.Context, args []string) error {
+		return genDocs(cmd.Cobra().Root(), DocFormat(format), dir)
+	}
+	return cmd
+}
@@ -0,0 +1,97 @@
+package cli
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/spf13/pflag"
+)
+
+func newDocsRoot() Commander {
+	return &Command{
+		Use:       "app",
+		Short:     "An example application.",
+		EnvPrefix: "APP",
+		Commands: []Commander{
+			&Command{
+				Use:       "serve",
+				Short:     "Serve requests.",
+				Example:   "  app serve web --port 80",
+				Arguments: []Arg{{Name: "name", Usage: "site name"}},
+				FlagSet: &FlagSet{Local: func(pfs *pflag.FlagSet) {
+					pfs.Int("port", 8080, "listen port")
+				}},
+				Run: func(ctx context.Context, args []string) error { return nil },
+			},
+			&Command{Use: "internal", Hidden: true},
+		},
+	}
+}
+
+func TestGenDocs(t *testing.T) {
+	dir := t.TempDir()
+	if err := GenDocs(newDocsRoot(), DocMarkdown, dir); err != nil {
+		t.Fatal(err)
+	}
+
+	data, err := os.ReadFile(filepath.Join(dir, "app_serve.md"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{
+		"app serve <name> [flags]",
+		"Arguments:\n  name   site name",
+		"app serve web --port 80",
+		"listen port [$APP_SERVE_PORT] (default 8080)",
+		"[app](app.md)",
+	} {
+		if !strings.Contains(string(data), want) {
+			t.Errorf("app_serve.md does not contain %q:\n%s", want, data)
+		}
+	}
+	if _, err := os.Stat(filepath.Join(dir, "app_internal.md")); !os.IsNotExist(err) {
+		t.Errorf("hidden command has docs, stat error = %v", err)
+	}
+
+	if err := GenDocs(newDocsRoot(), "pdf", dir); err == nil {
+		t.Error("GenDocs() with unsupported format returned nil error")
+	}
+}
+
+func TestDocsCommand(t *testing.T) {
+	dir := t.TempDir()
+	exec, err := NewCommand(newDocsRoot(), WithDocs(), WithArgs("docs", "--format", "man", "--dir", dir))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := exec.Execute(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	for _, name := range []string{"app.1", "app-serve.1"} {
+		if _, err := os.Stat(filepath.Join(dir, name)); err != nil {
+			t.Errorf("man page %s: %v", name, err)
+		}
+	}
+	if _, err := os.Stat(filepath.Join(dir, "app-docs.1")); !os.IsNotExist(err) {
+		t.Errorf("docs command has a man page, stat error = %v", err)
+	}
+}
+
+func TestGenDocs_KeepsCommandTree(t *testing.T) {
+	root := newDocsRoot()
+	if _, err := NewCommand(root); err != nil {
+		t.Fatal(err)
+	}
+	serve := root.Commanders()[0]
+	rootCobra, serveCobra := root.Cobra(), serve.Cobra()
+
+	if err := GenDocs(root, DocMarkdown, t.TempDir()); err != nil {
+		t.Fatal(err)
+	}
+	if root.Cobra() != rootCobra || serve.Cobra() != serveCobra {
+		t.Error("GenDocs replaced the cobra commands of the caller's tree")
+	}
+}
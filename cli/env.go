@@ -0,0 +1,57 @@
+package cli
+
+import (
+	"strings"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+)
+
+// envKeyReplacer 与 conf 的默认规则一致，将 flag 名称中的 . 与 - 替换为 _
+var envKeyReplacer = strings.NewReplacer(".", "_", "-", "_")
+
+// flagEnvPrefix 返回命令的环境变量前缀，未设置 EnvPrefix 的命令继承父命令的前缀并追加自身名称，
+// 例如根命令前缀为 APP 时，子命令 serve 的 flag --http-port 对应 APP_SERVE_HTTP_PORT
+func (cb *commandBuilder) flagEnvPrefix() string {
+	if prefix := optional[EnvCommander](cb.commander).FlagEnvPrefix(); prefix != "" {
+		return prefix
+	}
+	if cb.parent == nil {
+		return ""
+	}
+	prefix := cb.parent.flagEnvPrefix()
+	if prefix == "" {
+		return ""
+	}
+	return prefix + "_" + commandName(cb.commander.Usage())
+}
+
+func flagEnvName(prefix, flag string) string {
+	return strings.ToUpper(envKeyReplacer.Replace(prefix + "_" + flag))
+}
+
+// bindFlagEnv 为命令自身定义的 flag 添加环境变量，并在帮助信息中显示 [$ENV]
+// 通过 struct 标签声明的环境变量优先于按前缀生成的环境变量
+func (cb *commandBuilder) bindFlagEnv(ccmd *cobra.Command) {
+	prefix := cb.flagEnvPrefix()
+	visit := func(f *pflag.Flag) {
+		if prefix != "" {
+			if f.Annotations == nil {
+				f.Annotations = make(map[string][]string)
+			}
+			f.Annotations[flagEnvAnnotation] = append(f.Annotations[flagEnvAnnotation], flagEnvName(prefix, f.Name))
+		}
+
+		envs := f.Annotations[flagEnvAnnotation]
+		if len(envs) == 0 {
+			return
+		}
+		names := make([]string, 0, len(envs))
+		for _, env := range envs {
+			names = append(names, "$"+env)
+		}
+		f.Usage = strings.TrimSpace(f.Usage + " [" + strings.Join(names, ", ") + "]")
+	}
+	ccmd.Flags().VisitAll(visit)
+	ccmd.PersistentFlags().VisitAll(visit)
+}
@@ -0,0 +1,128 @@
+package cli
+
+import (
+	"bytes"
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+)
+
+func TestCommand_EnvPrefix(t *testing.T) {
+	t.Setenv("APP_SERVE_HTTP_PORT", "9090")
+	t.Setenv("APP_DATA_DIR", "/env")
+	t.Setenv("OTHER_NAME", "other")
+
+	tests := []struct {
+		name     string
+		args     []string
+		wantPort int
+		wantDir  string
+		wantName string
+	}{
+		{name: "env", args: []string{"serve"}, wantPort: 9090, wantDir: "/env"},
+		{name: "flag over env", args: []string{"serve", "--http-port", "1", "--data-dir", "/flag"}, wantPort: 1, wantDir: "/flag"},
+		{name: "own prefix", args: []string{"other"}, wantPort: 8080, wantDir: "/env", wantName: "other"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var (
+				port      int
+				dir, name string
+			)
+			root := &Command{
+				Use:       "app",
+				EnvPrefix: "APP",
+				FlagSet: &FlagSet{Persistent: func(pfs *pflag.FlagSet) {
+					pfs.StringVar(&dir, "data-dir", "/default", "data directory")
+				}},
+				Commands: []Commander{
+					&Command{
+						Use: "serve",
+						FlagSet: &FlagSet{Local: func(pfs *pflag.FlagSet) {
+							pfs.IntVar(&port, "http-port", 8080, "listen port")
+						}},
+						Run: func(ctx context.Context, args []string) error { return nil },
+					},
+					&Command{
+						Use:       "other",
+						EnvPrefix: "OTHER",
+						Run:       func(ctx context.Context, args []string) error { return nil },
+						FlagSet: &FlagSet{Local: func(pfs *pflag.FlagSet) {
+							pfs.StringVar(&name, "name", "", "")
+						}},
+					},
+				},
+			}
+			exec, err := NewCommand(root, WithArgs(tt.args...))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if err := exec.Execute(context.Background()); err != nil {
+				t.Fatal(err)
+			}
+			if port != tt.wantPort || dir != tt.wantDir || name != tt.wantName {
+				t.Errorf("port = %d, dir = %q, name = %q, want %d, %q, %q", port, dir, name, tt.wantPort, tt.wantDir, tt.wantName)
+			}
+		})
+	}
+}
+
+func TestCommand_EnvHelp(t *testing.T) {
+	var stdout bytes.Buffer
+	exec, err := NewCommand(&Command{
+		Use:       "app",
+		EnvPrefix: "APP",
+		Commands: []Commander{
+			&Command{
+				Use: "serve",
+				FlagSet: &FlagSet{Local: func(pfs *pflag.FlagSet) {
+					pfs.Int("http-port", 8080, "listen port")
+				}},
+				Run: func(ctx context.Context, args []string) error { return nil },
+			},
+		},
+	}, WithArgs("serve", "--help"), WithIOStreams(IOStreams{Out: &stdout}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := exec.Execute(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if want := "listen port [$APP_SERVE_HTTP_PORT] (default 8080)"; !strings.Contains(stdout.String(), want) {
+		t.Errorf("help output %q does not contain %q", stdout.String(), want)
+	}
+}
+
+func TestCommand_EnvRequiredFlag(t *testing.T) {
+	var name string
+	newExec := func() Exec {
+		exec, err := NewCommand(&Command{
+			Use:       "app",
+			EnvPrefix: "APP",
+			FlagSet: &FlagSet{Local: func(pfs *pflag.FlagSet) {
+				pfs.StringVar(&name, "name", "", "user name")
+				_ = cobra.MarkFlagRequired(pfs, "name")
+			}},
+			Run: func(ctx context.Context, args []string) error { return nil },
+		}, WithArgs(), WithIOStreams(IOStreams{Out: &bytes.Buffer{}, ErrOut: &bytes.Buffer{}}))
+		if err != nil {
+			t.Fatal(err)
+		}
+		return exec
+	}
+
+	if err := newExec().Execute(context.Background()); ExitCode(err) != ExitUsage {
+		t.Errorf("without env: got %v, want a usage error", err)
+	}
+
+	t.Setenv("APP_NAME", "x")
+	if err := newExec().Execute(context.Background()); err != nil {
+		t.Fatalf("required flag set by env: %v", err)
+	}
+	if name != "x" {
+		t.Errorf("name = %q, want x", name)
+	}
+}
@@ -0,0 +1,146 @@
+package cli
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+)
+
+// 进程退出码
+const (
+	ExitOK      = 0
+	ExitFailure = 1
+	// ExitUsage 命令用法错误：未知命令、flag 解析失败、位置参数不合法、选项校验失败
+	ExitUsage = 2
+	// ExitCanceled 命令因 context 取消（例如收到信号）而退出
+	ExitCanceled = 130
+	// ExitForced 收到第二次信号或超过 grace 时间后强制退出
+	ExitForced = 137
+)
+
+// ExitCoder 由携带进程退出码的错误实现
+type ExitCoder interface {
+	ExitCode() int
+}
+
+// ExitError 携带进程退出码的错误，例如插件进程的退出码；
+// Err 为 nil 时 Run 只返回退出码而不输出错误，用于已自行输出错误信息的情况
+type ExitError struct {
+	Code int
+	Err  error
+}
+
+func (e *ExitError) Error() string {
+	if e.Err == nil {
+		return fmt.Sprintf("exit status %d", e.Code)
+	}
+	return e.Err.Error()
+}
+
+func (e *ExitError) Unwrap() error {
+	return e.Err
+}
+
+// ExitCode implements ExitCoder.
+func (e *ExitError) ExitCode() int {
+	return e.Code
+}
+
+// UsageError 命令用法错误，Cmd 为出错命令的完整路径
+type UsageError struct {
+	Cmd string
+	Err error
+}
+
+func (e *UsageError) Error() string {
+	return e.Err.Error()
+}
+
+func (e *UsageError) Unwrap() error {
+	return e.Err
+}
+
+// ExitCode implements ExitCoder.
+func (e *UsageError) ExitCode() int {
+	return ExitUsage
+}
+
+// ExitCode 返回 err 对应的进程退出码
+// 依次判断: nil、ExitCoder、context 取消，其余错误返回 ExitFailure
+func ExitCode(err error) int {
+	if err == nil {
+		return ExitOK
+	}
+
+	var coder ExitCoder
+	if errors.As(err, &coder) {
+		return coder.ExitCode()
+	}
+	if errors.Is(err, context.Canceled) {
+		return ExitCanceled
+	}
+	return ExitFailure
+}
+
+func errorKind(err error) string {
+	var (
+		usageErr      *UsageError
+		argsErr       *ArgsError
+		validationErr *ValidationError
+	)
+	switch {
+	case errors.As(err, &usageErr), errors.As(err, &argsErr):
+		return "usage"
+	case errors.As(err, &validationErr):
+		return "validation"
+	case errors.Is(err, context.Canceled):
+		return "canceled"
+	default:
+		return "error"
+	}
+}
+
+// usageCmd 返回用法错误对应的命令路径
+func usageCmd(err error) (string, bool) {
+	var usageErr *UsageError
+	if errors.As(err, &usageErr) {
+		return usageErr.Cmd, true
+	}
+	var argsErr *ArgsError
+	if errors.As(err, &argsErr) {
+		return argsErr.Cmd, true
+	}
+	return "", false
+}
+
+type jsonError struct {
+	Error  string   `json:"error"`
+	Kind   string   `json:"kind"`
+	Code   int      `json:"code"`
+	Errors []string `json:"errors,omitempty"`
+}
+
+// printError 以文本或 JSON 格式输出错误，Err 为 nil 的 ExitError 不输出
+func (e *executor) printError(w io.Writer, err error, code int) {
+	if exitErr, ok := err.(*ExitError); ok && exitErr.Err == nil {
+		return
+	}
+	if e.opts.jsonErrors {
+		out := jsonError{Error: err.Error(), Kind: errorKind(err), Code: code}
+		var validationErr *ValidationError
+		if errors.As(err, &validationErr) {
+			for _, verr := range validationErr.Errs {
+				out.Errors = append(out.Errors, verr.Error())
+			}
+		}
+		_ = json.NewEncoder(w).Encode(out)
+		return
+	}
+
+	fmt.Fprintf(w, "Error: %v\n", err)
+	if cmd, ok := usageCmd(err); ok && e.opts.usageHints {
+		fmt.Fprintf(w, "Run '%s --help' for usage.\n", cmd)
+	}
+}
@@ -0,0 +1,110 @@
+package cli
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+)
+
+type exitOpts struct {
+	Name string `persistent:"true"`
+}
+
+func (o *exitOpts) Validate() []error {
+	if o.Name == "" {
+		return []error{errors.New("name is required")}
+	}
+	return nil
+}
+
+func TestExitCode(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+		want int
+	}{
+		{name: "nil", err: nil, want: ExitOK},
+		{name: "generic", err: errors.New("boom"), want: ExitFailure},
+		{name: "exit coder", err: fmt.Errorf("wrap: %w", &ExitError{Code: 7}), want: 7},
+		{name: "usage", err: &UsageError{Err: errors.New("bad flag")}, want: ExitUsage},
+		{name: "args", err: &ArgsError{Msg: "too many"}, want: ExitUsage},
+		{name: "validation", err: &ValidationError{Errs: []error{errors.New("x")}}, want: ExitUsage},
+		{name: "canceled", err: fmt.Errorf("run: %w", context.Canceled), want: ExitCanceled},
+		{name: "signal", err: &SignalError{}, want: ExitCanceled},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := ExitCode(tt.err); got != tt.want {
+				t.Errorf("ExitCode() = %d, want %d", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestExec_Run(t *testing.T) {
+	newRoot := func() Commander {
+		opts := &exitOpts{}
+		return &Command{
+			Use:     "app",
+			Options: opts,
+			FlagSet: FlagsFromStruct(opts),
+			Commands: []Commander{
+				&Command{Use: "fail", Run: func(ctx context.Context, args []string) error {
+					return &ExitError{Code: 5, Err: errors.New("failed")}
+				}},
+				&Command{Use: "login", FlagSet: &FlagSet{Local: func(pfs *pflag.FlagSet) {
+					pfs.String("user", "", "user name")
+					_ = cobra.MarkFlagRequired(pfs, "user")
+				}}},
+			},
+		}
+	}
+
+	tests := []struct {
+		name     string
+		args     []string
+		opts     []Option
+		wantCode int
+		wantErr  string
+	}{
+		{name: "unknown flag", args: []string{"--nope"}, opts: []Option{WithUsageHints()}, wantCode: ExitUsage,
+			wantErr: "Error: unknown flag: --nope\nRun 'app --help' for usage.\n"},
+		{name: "unknown command", args: []string{"nope", "--name", "x"}, wantCode: ExitUsage,
+			wantErr: "Error: unknown command \"nope\" for \"app\"\n"},
+		{name: "required flag", args: []string{"login", "--name", "x"}, wantCode: ExitUsage,
+			wantErr: "Error: required flag(s) \"user\" not set\n"},
+		{name: "validation", args: []string{}, wantCode: ExitUsage,
+			wantErr: "Error: invalid options for \"app\":\n  - name is required\n"},
+		{name: "exit coder", args: []string{"fail", "--name", "x"}, wantCode: 5,
+			wantErr: "Error: failed\n"},
+		{name: "json", args: []string{}, opts: []Option{WithJSONErrors()}, wantCode: ExitUsage,
+			wantErr: `{"error":"invalid options for \"app\":\n  - name is required","kind":"validation","code":2,"errors":["name is required"]}` + "\n"},
+		{name: "ok", args: []string{"--name", "x"}, wantCode: ExitOK},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var stderr bytes.Buffer
+			opts := append([]Option{
+				WithArgs(tt.args...),
+				WithIOStreams(IOStreams{In: strings.NewReader(""), Out: &bytes.Buffer{}, ErrOut: &stderr}),
+			}, tt.opts...)
+			exec, err := NewCommand(newRoot(), opts...)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if code := exec.Run(context.Background()); code != tt.wantCode {
+				t.Errorf("Run() = %d, want %d", code, tt.wantCode)
+			}
+			if stderr.String() != tt.wantErr {
+				t.Errorf("stderr = %q, want %q", stderr.String(), tt.wantErr)
+			}
+		})
+	}
+}
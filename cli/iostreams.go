@@ -0,0 +1,29 @@
+package cli
+
+import (
+	"context"
+	"io"
+	"os"
+)
+
+// IOStreams 命令使用的标准输入、输出与错误输出
+type IOStreams struct {
+	In     io.Reader
+	Out    io.Writer
+	ErrOut io.Writer
+}
+
+type ioContext int
+
+const ioKey ioContext = iota
+
+// IO 返回执行器注入到 context 中的标准流，命令应通过它读写以便被测试捕获
+// 未注入时返回进程的标准流
+func IO(ctx context.Context) IOStreams {
+	if ctx != nil {
+		if s, ok := ctx.Value(ioKey).(IOStreams); ok {
+			return s
+		}
+	}
+	return IOStreams{In: os.Stdin, Out: os.Stdout, ErrOut: os.Stderr}
+}
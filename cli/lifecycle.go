@@ -0,0 +1,150 @@
+package cli
+
+import (
+	"context"
+	"errors"
+
+	"github.com/spf13/cobra"
+)
+
+// 命令执行顺序:
+//
+//	flag env -> args -> log flags -> Inits -> PersistentPreRun(root -> leaf) -> prompt -> config -> Complete/Validate
+//	      -> PreRun -> confirm -> Run -> PostRun -> PersistentPostRun(leaf -> root) -> Teardown(leaf -> root)
+//
+// cobra 只会执行距离被执行命令最近的 PersistentPreRun/PersistentPostRun，
+// 因此每个命令都注册相同的钩子，由被执行命令沿 parent 链完成串联。
+// 交互会话中根命令的 PersistentPreRun 只在第一行运行，PersistentPostRun 与 Teardown 在会话结束时运行
+
+// walk 以先序遍历的方式访问命令树
+func (cb *commandBuilder) walk(fn func(cb *commandBuilder)) {
+	fn(cb)
+	for _, sub := range cb.subCmdBuilders {
+		sub.walk(fn)
+	}
+}
+
+// chain 返回从根命令到当前命令的 builder 列表
+func (cb *commandBuilder) chain() []*commandBuilder {
+	var chain []*commandBuilder
+	for b := cb; b != nil; b = b.parent {
+		chain = append([]*commandBuilder{b}, chain...)
+	}
+	return chain
+}
+
+// sessionChain 与 chain 相同，根命令的钩子由交互会话管理时不包含根命令
+func (cb *commandBuilder) sessionChain() []*commandBuilder {
+	chain := cb.chain()
+	if cb.session.rootStarted() {
+		return chain[1:]
+	}
+	return chain
+}
+
+// isCompletionRequest 判断是否为 cobra 内部的 __complete 命令，补全时不运行生命周期钩子
+func isCompletionRequest(cmd *cobra.Command) bool {
+	return cmd.Name() == cobra.ShellCompRequestCmd
+}
+
+func (cb *commandBuilder) persistentPreRun(cmd *cobra.Command, args []string) error {
+	if isCompletionRequest(cmd) {
+		return nil
+	}
+	if err := cb.setupLogger(cmd); err != nil {
+		return err
+	}
+	cb.commander.InitFunc()
+
+	cb.session.begin(cmd)
+	for _, b := range cb.chain() {
+		root := b.parent == nil
+		if root && cb.session.rootStarted() {
+			continue
+		}
+		if err := optional[HookedCommander](b.commander).PersistentPreFunc(cmd.Context(), args); err != nil {
+			return err
+		}
+		if root && cb.session != nil && cb.session.active {
+			cb.session.started = true
+		}
+	}
+	return nil
+}
+
+func (cb *commandBuilder) preRun(cmd *cobra.Command, args []string) error {
+	if err := cb.promptFlags(cmd); err != nil {
+		return err
+	}
+	if err := cb.loadConfig(cmd); err != nil {
+		return err
+	}
+	if err := completeAndValidate(cmd.CommandPath(), optional[OptionsCommander](cb.commander).Opts()); err != nil {
+		return err
+	}
+	return cb.commander.PreFunc(cmd.Context(), args)
+}
+
+func (cb *commandBuilder) run(cmd *cobra.Command, args []string) error {
+	if err := cb.confirm(cmd); err != nil {
+		return err
+	}
+	return cb.commander.RunFunc(cmd.Context(), args)
+}
+
+func (cb *commandBuilder) postRun(cmd *cobra.Command, args []string) error {
+	return optional[HookedCommander](cb.commander).PostFunc(cmd.Context(), args)
+}
+
+func (cb *commandBuilder) persistentPostRun(cmd *cobra.Command, args []string) error {
+	if isCompletionRequest(cmd) {
+		return nil
+	}
+	chain := cb.sessionChain()
+	for i := len(chain) - 1; i >= 0; i-- {
+		if err := optional[HookedCommander](chain[i].commander).PersistentPostFunc(cmd.Context(), args); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// teardown 从当前命令到根命令依次运行 Teardown，并与执行结果合并
+func (cb *commandBuilder) teardown(ctx context.Context, err error) error {
+	var errs []error
+	chain := cb.sessionChain()
+	for i := len(chain) - 1; i >= 0; i-- {
+		if terr := optional[HookedCommander](chain[i].commander).TeardownFunc(ctx, err); terr != nil {
+			errs = append(errs, terr)
+		}
+	}
+	if lo := cb.logOptions(); lo != nil {
+		if cerr := lo.close(); cerr != nil {
+			errs = append(errs, cerr)
+		}
+	}
+	if len(errs) == 0 {
+		return err
+	}
+	return errors.Join(append([]error{err}, errs...)...)
+}
+
+// loadConfig 从根命令到当前命令依次加载 Configer，--config 为 persistent flag，
+// 因此祖先命令的配置同样作用于子命令；祖先命令只绑定其自身定义的 flag
+func (cb *commandBuilder) loadConfig(cmd *cobra.Command) error {
+	file, _ := cmd.Flags().GetString(configFlagName)
+	for _, b := range cb.chain() {
+		cfgr := optional[ConfigCommander](b.commander).Configer()
+		if cfgr == nil {
+			continue
+		}
+		fs := cmd.Flags()
+		if b != cb {
+			fs = b.cobra.LocalFlags()
+		}
+		if err := cfgr.Load(file, fs); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,152 @@
+package cli
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+)
+
+func TestCommand_Lifecycle(t *testing.T) {
+	errRun := errors.New("run failed")
+
+	tests := []struct {
+		name    string
+		args    []string
+		runErr  error
+		want    []string
+		wantErr error
+	}{
+		{
+			name: "leaf",
+			args: []string{"sub"},
+			want: []string{
+				"sub.init",
+				"root.ppre", "sub.ppre",
+				"sub.pre", "sub.run", "sub.post",
+				"sub.ppost", "root.ppost",
+				"sub.teardown", "root.teardown",
+			},
+		},
+		{
+			name:   "teardown after error",
+			args:   []string{"sub"},
+			runErr: errRun,
+			want: []string{
+				"sub.init",
+				"root.ppre", "sub.ppre",
+				"sub.pre", "sub.run",
+				"sub.teardown", "root.teardown",
+			},
+			wantErr: errRun,
+		},
+		{
+			name: "root",
+			args: []string{},
+			want: []string{"root.init", "root.ppre", "root.pre", "root.run", "root.post", "root.ppost", "root.teardown"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got []string
+			record := func(name string) func(context.Context, []string) error {
+				return func(context.Context, []string) error {
+					got = append(got, name)
+					return nil
+				}
+			}
+			newCmd := func(name string, runErr error, subs ...Commander) *Command {
+				return &Command{
+					Use: name,
+					Inits: func() []func() {
+						return []func(){func() { got = append(got, name+".init") }}
+					},
+					PersistentPreRun: record(name + ".ppre"),
+					PreRun:           record(name + ".pre"),
+					Run: func(ctx context.Context, args []string) error {
+						got = append(got, name+".run")
+						return runErr
+					},
+					PostRun:           record(name + ".post"),
+					PersistentPostRun: record(name + ".ppost"),
+					Teardown: func(ctx context.Context, err error) error {
+						got = append(got, name+".teardown")
+						return nil
+					},
+					Commands: subs,
+				}
+			}
+
+			exec, err := NewCommand(newCmd("root", nil, newCmd("sub", tt.runErr)))
+			if err != nil {
+				t.Fatal(err)
+			}
+			exec.(*executor).cobra.SetArgs(tt.args)
+			if err := exec.Execute(context.Background()); !errors.Is(err, tt.wantErr) {
+				t.Fatalf("got error %v, want %v", err, tt.wantErr)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("got %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+type validateTestOptions struct {
+	Name string
+	Port int
+}
+
+func (o *validateTestOptions) Complete() {
+	if o.Name == "" {
+		o.Name = "default"
+	}
+}
+
+func (o *validateTestOptions) Validate() []error {
+	var errs []error
+	if o.Port <= 0 {
+		errs = append(errs, errors.New("port must be positive"))
+	}
+	if o.Name == "forbidden" {
+		errs = append(errs, errors.New("name is forbidden"))
+	}
+	return errs
+}
+
+func TestCommand_CompleteValidate(t *testing.T) {
+	opts := &validateTestOptions{Name: "forbidden"}
+	preRun := false
+	exec, err := NewCommand(&Command{
+		Use:     "app",
+		Options: opts,
+		PreRun: func(ctx context.Context, args []string) error {
+			preRun = true
+			return nil
+		},
+	}, WithArgs())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	err = exec.Execute(context.Background())
+	var verr *ValidationError
+	if !errors.As(err, &verr) || len(verr.Errs) != 2 {
+		t.Fatalf("got %v, want ValidationError with 2 errors", err)
+	}
+	if verr.ExitCode() != ExitUsage {
+		t.Errorf("exit code = %d, want %d", verr.ExitCode(), ExitUsage)
+	}
+	if preRun {
+		t.Error("PreRun called after validation failed")
+	}
+
+	opts.Name, opts.Port = "", 80
+	if err := exec.Execute(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if !preRun || opts.Name != "default" {
+		t.Errorf("preRun = %v, name = %q, want Complete to run before PreRun", preRun, opts.Name)
+	}
+}
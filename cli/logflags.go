@@ -0,0 +1,129 @@
+package cli
+
+import (
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/chhz0/goose/log"
+	"github.com/spf13/cobra"
+)
+
+const (
+	logLevelFlag  = "log-level"
+	logFormatFlag = "log-format"
+	logFileFlag   = "log-file"
+	logRotateFlag = "log-rotate"
+)
+
+// logOptions 由 --log-* flag 填充，用于配置 log 包的默认 logger
+type logOptions struct {
+	level  string
+	format string
+	file   string
+	rotate string
+
+	// closer 为本次执行打开的日志文件, prev 为替换前的默认 logger, 在 Teardown 时恢复
+	closer io.Closer
+	prev   log.Logger
+}
+
+func (lo *logOptions) addFlags(ccmd *cobra.Command) {
+	fs := ccmd.PersistentFlags()
+	fs.StringVar(&lo.level, logLevelFlag, "info", "log level: debug|info|warn|error")
+	fs.StringVar(&lo.format, logFormatFlag, "console", "log format: console|json")
+	fs.StringVar(&lo.file, logFileFlag, "", "write logs to the file instead of stderr")
+	fs.StringVar(&lo.rotate, logRotateFlag, "", "rotate the log file by size|time, requires --log-file")
+}
+
+// logger 根据 flag 创建 logger，未指定 --log-file 时写入 stderr
+// 打开的日志文件记录在 closer 中，由 close 关闭
+func (lo *logOptions) logger(stderr io.Writer) (log.Logger, error) {
+	var level log.Level
+	if err := level.UnmarshalText([]byte(lo.level)); err != nil {
+		return nil, fmt.Errorf("invalid --%s %q", logLevelFlag, lo.level)
+	}
+
+	var encoder log.LogEncoder
+	switch lo.format {
+	case "console":
+		encoder = log.ConsoleEncoder
+	case "json":
+		encoder = log.JsonEncoder
+	default:
+		return nil, fmt.Errorf("invalid --%s %q, must be one of: console|json", logFormatFlag, lo.format)
+	}
+
+	out, err := lo.output(stderr)
+	if err != nil {
+		return nil, err
+	}
+	if c, ok := out.(io.Closer); ok && out != stderr {
+		lo.closer = c
+	}
+	return log.NewLogger(func() io.Writer { return out }, level, encoder,
+		log.WithCaller(true), log.AddCallerSkip(1)), nil
+}
+
+func (lo *logOptions) output(stderr io.Writer) (io.Writer, error) {
+	if lo.file == "" {
+		if lo.rotate != "" {
+			return nil, fmt.Errorf("--%s requires --%s", logRotateFlag, logFileFlag)
+		}
+		return stderr, nil
+	}
+
+	switch lo.rotate {
+	case "":
+		return os.OpenFile(lo.file, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o644)
+	case "size":
+		return log.NewRotateBySize(log.NewProductionRotateConfig(lo.file)), nil
+	case "time":
+		w := log.NewRotateByTime(log.NewProductionRotateConfig(lo.file))
+		if w == nil {
+			return nil, fmt.Errorf("cannot rotate log file %q", lo.file)
+		}
+		return w, nil
+	default:
+		return nil, fmt.Errorf("invalid --%s %q, must be one of: size|time", logRotateFlag, lo.rotate)
+	}
+}
+
+// logOptions 返回距离当前命令最近的启用了 log flag 的命令的配置
+func (cb *commandBuilder) logOptions() *logOptions {
+	for b := cb; b != nil; b = b.parent {
+		if b.logOpts != nil {
+			return b.logOpts
+		}
+	}
+	return nil
+}
+
+// setupLogger 在 Inits 之前替换 log 包的默认 logger
+func (cb *commandBuilder) setupLogger(cmd *cobra.Command) error {
+	lo := cb.logOptions()
+	if lo == nil {
+		return nil
+	}
+
+	l, err := lo.logger(cmd.ErrOrStderr())
+	if err != nil {
+		return &UsageError{Cmd: cmd.CommandPath(), Err: err}
+	}
+	lo.prev = log.ZapLogger()
+	log.ReplaceDefault(l)
+	return nil
+}
+
+// close 刷新日志并关闭本次执行打开的日志文件，之后恢复原来的默认 logger，
+// 避免 shell 模式下每一行命令都泄漏一个文件句柄
+func (lo *logOptions) close() error {
+	log.Sync()
+	if lo.closer == nil {
+		return nil
+	}
+	log.ReplaceDefault(lo.prev)
+	err := lo.closer.Close()
+	lo.closer, lo.prev = nil, nil
+	return err
+}
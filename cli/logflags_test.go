@@ -0,0 +1,190 @@
+package cli
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/chhz0/goose/log"
+)
+
+func TestCommand_LogFlags(t *testing.T) {
+	std := log.ZapLogger()
+	t.Cleanup(func() { log.ReplaceDefault(std) })
+
+	logFile := filepath.Join(t.TempDir(), "app.log")
+	tests := []struct {
+		name     string
+		args     []string
+		wantErr  bool
+		wantLog  []string
+		wantNone []string
+	}{
+		{name: "default level", args: []string{"sub"}, wantLog: []string{"info msg"}, wantNone: []string{"debug msg"}},
+		{name: "debug json", args: []string{"sub", "--log-level", "debug", "--log-format", "json"},
+			wantLog: []string{`"msg":"debug msg"`, `"msg":"info msg"`}},
+		{name: "file", args: []string{"sub", "--log-file", logFile}},
+		{name: "invalid level", args: []string{"sub", "--log-level", "loud"}, wantErr: true},
+		{name: "rotate without file", args: []string{"sub", "--log-rotate", "size"}, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			root := &Command{
+				Use:            "app",
+				EnableLogFlags: true,
+				Commands: []Commander{
+					&Command{Use: "sub", Run: func(ctx context.Context, args []string) error {
+						log.Debug("debug msg")
+						log.Info("info msg")
+						return nil
+					}},
+				},
+			}
+
+			var stderr bytes.Buffer
+			exec, err := NewCommand(root, WithArgs(tt.args...), WithIOStreams(IOStreams{ErrOut: &stderr}))
+			if err != nil {
+				t.Fatal(err)
+			}
+			err = exec.Execute(context.Background())
+			if tt.wantErr {
+				var usageErr *UsageError
+				if !errors.As(err, &usageErr) {
+					t.Fatalf("Execute() error = %v, want UsageError", err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Execute() error = %v", err)
+			}
+
+			for _, want := range tt.wantLog {
+				if !strings.Contains(stderr.String(), want) {
+					t.Errorf("log output %q does not contain %q", stderr.String(), want)
+				}
+			}
+			for _, none := range tt.wantNone {
+				if strings.Contains(stderr.String(), none) {
+					t.Errorf("log output %q contains %q", stderr.String(), none)
+				}
+			}
+		})
+	}
+
+	data, err := os.ReadFile(logFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(data), "info msg") {
+		t.Errorf("log file = %q, want info msg", data)
+	}
+}
+
+func newLogRoot() Commander {
+	return &Command{
+		Use:            "app",
+		EnableLogFlags: true,
+		Commands: []Commander{
+			&Command{Use: "sub", Run: func(ctx context.Context, args []string) error {
+				log.Info("info msg")
+				return nil
+			}},
+		},
+	}
+}
+
+func TestCommand_LogRotate(t *testing.T) {
+	std := log.ZapLogger()
+	t.Cleanup(func() { log.ReplaceDefault(std) })
+
+	tests := []struct {
+		rotate string
+		glob   string
+	}{
+		{rotate: "size", glob: "app.log"},
+		// 目录名中的 "." 不影响按时间轮转的文件名
+		{rotate: "time", glob: "app.*.log"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.rotate, func(t *testing.T) {
+			dir := filepath.Join(t.TempDir(), "logs.d")
+			if err := os.Mkdir(dir, 0o755); err != nil {
+				t.Fatal(err)
+			}
+			logFile := filepath.Join(dir, "app.log")
+
+			exec, err := NewCommand(newLogRoot(),
+				WithArgs("sub", "--log-file", logFile, "--log-rotate", tt.rotate),
+				WithIOStreams(IOStreams{ErrOut: &bytes.Buffer{}}))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if err := exec.Execute(context.Background()); err != nil {
+				t.Fatal(err)
+			}
+
+			files, _ := filepath.Glob(filepath.Join(dir, tt.glob))
+			if len(files) != 1 {
+				t.Fatalf("log files = %v, want one matching %s", files, tt.glob)
+			}
+			data, err := os.ReadFile(files[0])
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !strings.Contains(string(data), "info msg") {
+				t.Errorf("log file = %q, want info msg", data)
+			}
+		})
+	}
+}
+
+func TestShell_LogFlags(t *testing.T) {
+	std := log.ZapLogger()
+	t.Cleanup(func() { log.ReplaceDefault(std) })
+
+	fds := func() int {
+		entries, err := os.ReadDir("/proc/self/fd")
+		if err != nil {
+			t.Skip("cannot count open files:", err)
+		}
+		return len(entries)
+	}
+
+	logFile := filepath.Join(t.TempDir(), "app.log")
+	lines := []string{"sub"}
+	for range 5 {
+		lines = append(lines, "sub --log-file "+logFile)
+	}
+	lines = append(lines, "sub")
+
+	before := fds()
+	var stderr bytes.Buffer
+	exec, err := Shell(newLogRoot(), WithIOStreams(IOStreams{
+		In: strings.NewReader(strings.Join(lines, "\n")), Out: &bytes.Buffer{}, ErrOut: &stderr,
+	}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := exec.Execute(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+
+	if after := fds(); after > before {
+		t.Errorf("open files grew from %d to %d, log files are not closed", before, after)
+	}
+	data, err := os.ReadFile(logFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if n := strings.Count(string(data), "info msg"); n != 5 {
+		t.Errorf("log file has %d messages, want 5", n)
+	}
+	// 第一行与最后一行没有 --log-file，写入 stderr
+	if n := strings.Count(stderr.String(), "info msg"); n != 2 {
+		t.Errorf("stderr has %d messages, want 2:\n%s", n, stderr.String())
+	}
+}
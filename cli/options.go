@@ -0,0 +1,144 @@
+package cli
+
+import (
+	"context"
+	"os"
+	"syscall"
+	"time"
+
+	"github.com/spf13/cobra"
+)
+
+// Option 配置 NewCommand 返回的执行器
+type Option func(*options)
+
+type options struct {
+	signals     []os.Signal
+	gracePeriod time.Duration
+
+	plugins    bool
+	pluginDirs []string
+
+	args    []string
+	streams IOStreams
+
+	jsonErrors bool
+	usageHints bool
+
+	shell bool
+	docs  bool
+
+	prompter Prompter
+	teardown func(ctx context.Context, err error) error
+
+	// detached 为 true 时不修改 Command 的 cobra 字段，用于 GenDocs 等不执行命令的场景
+	detached bool
+}
+
+func defaultOptions() *options {
+	return &options{}
+}
+
+// WithSignals 使 Execute 在收到信号时取消传递给命令的 context，
+// 命令需要在 grace 时间内返回，grace 为 0 表示不限制；
+// 再次收到信号或超时后以 ExitForced 强制退出进程。
+// 未指定信号时使用 SIGINT 与 SIGTERM
+func WithSignals(grace time.Duration, sigs ...os.Signal) Option {
+	return func(o *options) {
+		if len(sigs) == 0 {
+			sigs = []os.Signal{os.Interrupt, syscall.SIGTERM}
+		}
+		o.signals = sigs
+		o.gracePeriod = grace
+	}
+}
+
+// WithPlugins 启用插件发现：在 dirs 与 PATH 中查找名为 <root>-<sub> 的可执行文件，
+// 并以子命令 sub 的形式暴露。插件继承环境变量与标准流，退出码通过 ExitError 透传
+func WithPlugins(dirs ...string) Option {
+	return func(o *options) {
+		o.plugins = true
+		o.pluginDirs = dirs
+	}
+}
+
+// WithArgs 使用 args 代替 os.Args[1:] 作为命令行参数
+func WithArgs(args ...string) Option {
+	return func(o *options) {
+		o.args = append([]string{}, args...)
+	}
+}
+
+// WithIOStreams 替换命令使用的标准流，为 nil 的字段保持默认值
+func WithIOStreams(streams IOStreams) Option {
+	return func(o *options) {
+		o.streams = streams
+	}
+}
+
+// WithJSONErrors 使 Run 以单行 JSON 输出错误，便于脚本解析：
+// {"error":"...","kind":"usage|validation|canceled|error","code":2,"errors":[...]}
+func WithJSONErrors() Option {
+	return func(o *options) {
+		o.jsonErrors = true
+	}
+}
+
+// WithUsageHints 使 Run 在用法错误后提示查看 --help
+func WithUsageHints() Option {
+	return func(o *options) {
+		o.usageHints = true
+	}
+}
+
+// WithShell 在根命令下添加 shell 子命令，执行后进入交互模式，参见 Shell
+func WithShell() Option {
+	return func(o *options) {
+		o.shell = true
+	}
+}
+
+// WithDocs 在根命令下添加隐藏的 docs 子命令，参见 GenDocs
+func WithDocs() Option {
+	return func(o *options) {
+		o.docs = true
+	}
+}
+
+// WithPrompter 指定 PromptFlags 与 Confirm 使用的 Prompter，
+// 指定后即使标准输入不是终端也会询问，默认仅在标准输入为终端时询问
+func WithPrompter(p Prompter) Option {
+	return func(o *options) {
+		o.prompter = p
+	}
+}
+
+// WithTeardown 在命令树的 Teardown 之后运行 fn，err 为最终的执行结果，
+// 包括命令树之外的错误，例如未知命令与 flag 解析错误；fn 返回的错误与 err 合并
+func WithTeardown(fn func(ctx context.Context, err error) error) Option {
+	return func(o *options) {
+		o.teardown = fn
+	}
+}
+
+// withDetached 构建独立的命令树，Command.Cobra 仍指向调用方此前构建的命令树
+func withDetached() Option {
+	return func(o *options) {
+		o.detached = true
+	}
+}
+
+func (o *options) apply(root *cobra.Command) {
+	if o.args != nil {
+		root.SetArgs(o.args)
+	}
+	if o.streams.In != nil {
+		root.SetIn(o.streams.In)
+	}
+	if o.streams.Out != nil {
+		root.SetOut(o.streams.Out)
+	}
+	if o.streams.ErrOut != nil {
+		root.SetErr(o.streams.ErrOut)
+	}
+}
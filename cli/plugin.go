@@ -0,0 +1,118 @@
+package cli
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	osexec "os/exec"
+	"path/filepath"
+	"runtime"
+	"strings"
+
+	"github.com/spf13/cobra"
+)
+
+// discoverPlugins 在 dirs 与 PATH 中查找名为 <root>-<sub> 的可执行文件
+// 返回 sub 到可执行文件路径的映射，同名插件以先找到的为准
+func discoverPlugins(root string, dirs []string) map[string]string {
+	prefix := root + "-"
+	plugins := make(map[string]string)
+
+	search := append(append([]string{}, dirs...), filepath.SplitList(os.Getenv("PATH"))...)
+	for _, dir := range search {
+		if dir == "" {
+			continue
+		}
+		entries, err := os.ReadDir(dir)
+		if err != nil {
+			continue
+		}
+
+		for _, entry := range entries {
+			name := entry.Name()
+			if !strings.HasPrefix(name, prefix) {
+				continue
+			}
+			if runtime.GOOS == "windows" {
+				name = strings.TrimSuffix(name, filepath.Ext(name))
+			}
+			sub := strings.TrimPrefix(name, prefix)
+			if sub == "" {
+				continue
+			}
+			if _, ok := plugins[sub]; ok {
+				continue
+			}
+
+			path := filepath.Join(dir, entry.Name())
+			if isExecutable(path) {
+				plugins[sub] = path
+			}
+		}
+	}
+
+	return plugins
+}
+
+func isExecutable(path string) bool {
+	info, err := os.Stat(path)
+	if err != nil || !info.Mode().IsRegular() {
+		return false
+	}
+	if runtime.GOOS == "windows" {
+		ext := strings.ToLower(filepath.Ext(path))
+		return ext == ".exe" || ext == ".bat" || ext == ".cmd"
+	}
+	return info.Mode().Perm()&0o111 != 0
+}
+
+// addPlugins 将发现的插件作为子命令添加到根命令，已存在的同名子命令优先
+func addPlugins(root *cobra.Command, dirs []string) {
+	for sub, path := range discoverPlugins(root.Name(), dirs) {
+		if cmd, _, err := root.Find([]string{sub}); err == nil && cmd != root {
+			continue
+		}
+		root.AddCommand(pluginCommand(sub, path))
+	}
+}
+
+func pluginCommand(sub, path string) *cobra.Command {
+	return &cobra.Command{
+		Use:                sub,
+		Short:              fmt.Sprintf("Plugin provided by %s", path),
+		DisableFlagParsing: true,
+		SilenceErrors:      true,
+		SilenceUsage:       true,
+		// 插件是独立的进程，不运行根命令的钩子
+		PersistentPreRun:  func(*cobra.Command, []string) {},
+		PersistentPostRun: func(*cobra.Command, []string) {},
+		ValidArgsFunction: func(*cobra.Command, []string, string) ([]string, cobra.ShellCompDirective) {
+			return nil, cobra.ShellCompDirectiveDefault
+		},
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return runPlugin(cmd, path, args)
+		},
+	}
+}
+
+// runPlugin 以继承的环境变量与标准流运行插件，并透传其退出码，
+// 插件以非零状态退出时错误信息由插件自行输出
+func runPlugin(cmd *cobra.Command, path string, args []string) error {
+	c := osexec.CommandContext(cmd.Context(), path, args...)
+	c.Stdin = cmd.InOrStdin()
+	c.Stdout = cmd.OutOrStdout()
+	c.Stderr = cmd.ErrOrStderr()
+	c.Env = os.Environ()
+	c.Cancel = func() error {
+		return c.Process.Signal(os.Interrupt)
+	}
+
+	if err := c.Run(); err != nil {
+		var exitErr *osexec.ExitError
+		if errors.As(err, &exitErr) {
+			return &ExitError{Code: exitErr.ExitCode()}
+		}
+		return fmt.Errorf("plugin %s: %w", cmd.Name(), err)
+	}
+	return nil
+}
@@ -0,0 +1,71 @@
+//go:build unix
+
+package cli
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestPlugins(t *testing.T) {
+	dir := t.TempDir()
+	script := "#!/bin/sh\necho \"args=$* env=$PLUGIN_TEST_ENV\"\nexit 3\n"
+	if err := os.WriteFile(filepath.Join(dir, "app-hello"), []byte(script), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "app-noexec"), []byte(script), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "app-version"), []byte(script), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("PATH", "")
+	t.Setenv("PLUGIN_TEST_ENV", "inherited")
+
+	exec, err := NewCommand(&Command{
+		Use:      "app",
+		Commands: []Commander{VersionCommand()},
+	}, WithPlugins(dir))
+	if err != nil {
+		t.Fatal(err)
+	}
+	root := exec.(*executor).cobra
+
+	for name, want := range map[string]bool{"hello": true, "noexec": false} {
+		cmd, _, err := root.Find([]string{name})
+		if found := err == nil && cmd != root; found != want {
+			t.Errorf("plugin %q found = %v, want %v", name, found, want)
+		}
+	}
+	if cmd, _, _ := root.Find([]string{"version"}); cmd.DisableFlagParsing {
+		t.Error("builtin version command was replaced by plugin")
+	}
+
+	var out bytes.Buffer
+	root.SetOut(&out)
+	root.SetArgs([]string{"hello", "--flag", "x"})
+	err = exec.Execute(context.Background())
+
+	var exitErr *ExitError
+	if !errors.As(err, &exitErr) || exitErr.ExitCode() != 3 {
+		t.Fatalf("got %v, want exit code 3", err)
+	}
+	if got, want := out.String(), "args=--flag x env=inherited\n"; got != want {
+		t.Errorf("got output %q, want %q", got, want)
+	}
+
+	// 插件的退出码原样返回，不再额外输出错误
+	var errOut bytes.Buffer
+	root.SetErr(&errOut)
+	root.SetArgs([]string{"hello"})
+	if code := exec.Run(context.Background()); code != 3 {
+		t.Errorf("Run() = %d, want 3", code)
+	}
+	if errOut.Len() != 0 {
+		t.Errorf("stderr = %q, want empty", errOut.String())
+	}
+}
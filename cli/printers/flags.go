@@ -0,0 +1,86 @@
+package printers
+
+import (
+	"io"
+	"strings"
+
+	"github.com/chhz0/goose/meta"
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+)
+
+// PrintFlags 注册 --output/-o 与 --no-headers，并据此创建 Printer
+//
+//	pf := printers.NewPrintFlags(printers.Table)
+//	cmd := &cli.Command{
+//		FlagSet: &cli.FlagSet{Local: pf.AddFlags},
+//		Run: func(ctx context.Context, args []string) error {
+//			return pf.Print(cli.IO(ctx).Out, items)
+//		},
+//	}
+type PrintFlags struct {
+	Output       string
+	TableOptions meta.TableOptions
+}
+
+// NewPrintFlags 创建 PrintFlags，output 为 -o 的默认值
+func NewPrintFlags(output string) *PrintFlags {
+	return &PrintFlags{Output: output}
+}
+
+// AddFlags 注册 --output/-o 与 --no-headers
+func (f *PrintFlags) AddFlags(fs *pflag.FlagSet) {
+	fs.VarP(&outputValue{p: &f.Output}, "output", "o",
+		"output format, one of: json|yaml|table|wide|jsonpath=...|jsonpath-file=...|go-template=...|go-template-file=...")
+	fs.BoolVar(&f.TableOptions.NoHeaders, "no-headers", f.TableOptions.NoHeaders, "don't print headers in table output")
+}
+
+// ToPrinter 根据 flag 的值创建 Printer
+func (f *PrintFlags) ToPrinter() (Printer, error) {
+	return NewPrinter(f.Output, f.TableOptions)
+}
+
+// Print 以 flag 指定的格式将 v 输出到 w
+func (f *PrintFlags) Print(w io.Writer, v any) error {
+	p, err := f.ToPrinter()
+	if err != nil {
+		return err
+	}
+	return p.Print(w, v)
+}
+
+// outputValue 在解析时校验 -o 的格式，并为其提供补全
+type outputValue struct {
+	p *string
+}
+
+func (v *outputValue) Set(s string) error {
+	format, _, _ := strings.Cut(s, exprSeparator)
+	switch format {
+	case JSONPathFile, GoTemplateFile, JSONPath, GoTemplate:
+		// 表达式在创建 Printer 时校验
+	default:
+		if _, err := NewPrinter(s, meta.TableOptions{}); err != nil {
+			return err
+		}
+	}
+	*v.p = s
+	return nil
+}
+
+func (v *outputValue) String() string { return *v.p }
+func (v *outputValue) Type() string   { return "format" }
+
+// Complete 实现 cli.ValueCompleter
+func (v *outputValue) Complete(toComplete string) ([]string, cobra.ShellCompDirective) {
+	completions := make([]string, 0, len(Formats))
+	for _, format := range Formats {
+		switch format {
+		case JSONPath, JSONPathFile, GoTemplate, GoTemplateFile:
+			completions = append(completions, format+exprSeparator)
+		default:
+			completions = append(completions, format)
+		}
+	}
+	return completions, cobra.ShellCompDirectiveNoFileComp | cobra.ShellCompDirectiveNoSpace
+}
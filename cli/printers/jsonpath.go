@@ -0,0 +1,118 @@
+package printers
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+
+	"k8s.io/client-go/util/jsonpath"
+)
+
+// jsonPathPrinter 使用与 kubectl 相同的 JSONPath 实现，例如:
+//
+//	{.a.b}                                  字段，不存在时报错
+//	{.items[0]}、{.items[-1]}               数组下标，支持负数
+//	{.items[*].name}                        数组或对象的全部元素
+//	{range .items[*]}{.name}{"\n"}{end}     遍历
+//	{.items[?(@.port==80)].name}            过滤
+//
+// 花括号之外的文本原样输出，其中的 \n 与 \t 会被转义；多个结果以空格分隔
+type jsonPathPrinter struct {
+	jp *jsonpath.JSONPath
+}
+
+// NewJSONPathPrinter 创建 jsonpath Printer，表达式作用于值的 JSON 形式
+func NewJSONPathPrinter(expr string) (Printer, error) {
+	jp := jsonpath.New("jsonpath")
+	if err := jp.Parse(unescapeText(expr)); err != nil {
+		return nil, fmt.Errorf("invalid jsonpath %q: %w", expr, err)
+	}
+	return &jsonPathPrinter{jp: jp}, nil
+}
+
+// unescapeText 转义花括号之外的 \n 与 \t，花括号之内的字符串字面量由 jsonpath 自行解析
+func unescapeText(expr string) string {
+	var sb strings.Builder
+	inside, quote := false, byte(0)
+	for i := 0; i < len(expr); i++ {
+		c := expr[i]
+		switch {
+		case inside && quote != 0:
+			if c == '\\' && i+1 < len(expr) {
+				sb.WriteByte(c)
+				i++
+				c = expr[i]
+			} else if c == quote {
+				quote = 0
+			}
+		case inside:
+			if c == '"' || c == '\'' {
+				quote = c
+			} else if c == '}' {
+				inside = false
+			}
+		case c == '{':
+			inside = true
+		case c == '\\' && i+1 < len(expr) && (expr[i+1] == 'n' || expr[i+1] == 't'):
+			i++
+			if expr[i] == 'n' {
+				c = '\n'
+			} else {
+				c = '\t'
+			}
+		}
+		sb.WriteByte(c)
+	}
+	return sb.String()
+}
+
+func (p *jsonPathPrinter) Print(w io.Writer, v any) error {
+	data, err := jsonPathData(v)
+	if err != nil {
+		return err
+	}
+	// 先写入缓冲区，出错时不输出部分结果
+	var buf bytes.Buffer
+	if err := p.jp.Execute(&buf, data); err != nil {
+		return fmt.Errorf("jsonpath: %w", err)
+	}
+	_, err = buf.WriteTo(w)
+	return err
+}
+
+// jsonPathData 与 generic 相同，但整数解码为 int64，使过滤表达式可以与整数比较，与 kubectl 一致
+func jsonPathData(v any) (any, error) {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber()
+	var out any
+	if err := dec.Decode(&out); err != nil {
+		return nil, err
+	}
+	return convertNumbers(out), nil
+}
+
+func convertNumbers(v any) any {
+	switch t := v.(type) {
+	case map[string]any:
+		for k, val := range t {
+			t[k] = convertNumbers(val)
+		}
+	case []any:
+		for i, val := range t {
+			t[i] = convertNumbers(val)
+		}
+	case json.Number:
+		if n, err := t.Int64(); err == nil {
+			return n
+		}
+		f, _ := t.Float64()
+		return f
+	}
+	return v
+}
@@ -0,0 +1,195 @@
+// Package printers 以 json、yaml、table、wide、jsonpath、go-template 格式输出任意值或切片
+package printers
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"text/template"
+
+	"github.com/chhz0/goose/meta"
+	"gopkg.in/yaml.v2"
+)
+
+// 输出格式，jsonpath 与 go-template 以 "format=表达式" 的形式指定，
+// 例如 jsonpath={.items[*].name}、go-template={{.name}}，
+// 带 -file 后缀时表达式从文件读取，例如 go-template-file=tmpl.txt
+const (
+	JSON           = "json"
+	YAML           = "yaml"
+	Table          = "table"
+	Wide           = "wide"
+	JSONPath       = "jsonpath"
+	JSONPathFile   = "jsonpath-file"
+	GoTemplate     = "go-template"
+	GoTemplateFile = "go-template-file"
+)
+
+const exprSeparator = "="
+
+// Formats 全部支持的输出格式
+var Formats = []string{JSON, YAML, Table, Wide, JSONPath, JSONPathFile, GoTemplate, GoTemplateFile}
+
+// Printer 将 v 输出到 w
+type Printer interface {
+	Print(w io.Writer, v any) error
+}
+
+// PrinterFunc 将函数适配为 Printer
+type PrinterFunc func(w io.Writer, v any) error
+
+func (fn PrinterFunc) Print(w io.Writer, v any) error {
+	return fn(w, v)
+}
+
+// NewPrinter 根据 -o 的值创建 Printer，output 为空时使用 table
+func NewPrinter(output string, opts meta.TableOptions) (Printer, error) {
+	format, expr, hasExpr := strings.Cut(output, exprSeparator)
+	switch format {
+	case JSONPathFile, GoTemplateFile:
+		if !hasExpr || expr == "" {
+			return nil, fmt.Errorf("output %s requires a file, e.g. %s=path", format, format)
+		}
+		data, err := os.ReadFile(expr)
+		if err != nil {
+			return nil, err
+		}
+		format, expr = strings.TrimSuffix(format, "-file"), string(data)
+	case JSONPath, GoTemplate:
+		if !hasExpr || expr == "" {
+			return nil, fmt.Errorf("output %s requires an expression, e.g. %s=...", format, format)
+		}
+	default:
+		if hasExpr {
+			return nil, fmt.Errorf("output %s does not accept an expression", format)
+		}
+	}
+
+	switch format {
+	case JSON:
+		return PrinterFunc(printJSON), nil
+	case YAML:
+		return PrinterFunc(printYAML), nil
+	case "", Table:
+		return &TablePrinter{NoHeaders: opts.NoHeaders}, nil
+	case Wide:
+		return &TablePrinter{NoHeaders: opts.NoHeaders, Wide: true}, nil
+	case JSONPath:
+		return NewJSONPathPrinter(expr)
+	case GoTemplate:
+		return NewTemplatePrinter(expr)
+	default:
+		return nil, fmt.Errorf("unsupported output format %q, must be one of: %s", format, strings.Join(Formats, "|"))
+	}
+}
+
+func printJSON(w io.Writer, v any) error {
+	data, err := json.MarshalIndent(v, "", "  ")
+	if err != nil {
+		return err
+	}
+	_, err = fmt.Fprintf(w, "%s\n", data)
+	return err
+}
+
+// printYAML 先转换为 JSON，使字段名与 json 标签及 jsonpath 保持一致，并保留字段顺序
+func printYAML(w io.Writer, v any) error {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber()
+	ordered, err := decodeOrdered(dec)
+	if err != nil {
+		return err
+	}
+
+	out, err := yaml.Marshal(ordered)
+	if err != nil {
+		return err
+	}
+	_, err = w.Write(out)
+	return err
+}
+
+func decodeOrdered(dec *json.Decoder) (any, error) {
+	tok, err := dec.Token()
+	if err != nil {
+		return nil, err
+	}
+
+	switch t := tok.(type) {
+	case json.Delim:
+		if t == '{' {
+			ms := yaml.MapSlice{}
+			for dec.More() {
+				key, err := dec.Token()
+				if err != nil {
+					return nil, err
+				}
+				val, err := decodeOrdered(dec)
+				if err != nil {
+					return nil, err
+				}
+				ms = append(ms, yaml.MapItem{Key: key, Value: val})
+			}
+			_, err := dec.Token()
+			return ms, err
+		}
+
+		s := []any{}
+		for dec.More() {
+			val, err := decodeOrdered(dec)
+			if err != nil {
+				return nil, err
+			}
+			s = append(s, val)
+		}
+		_, err := dec.Token()
+		return s, err
+	case json.Number:
+		if i, err := t.Int64(); err == nil {
+			return i, nil
+		}
+		return t.Float64()
+	default:
+		return t, nil
+	}
+}
+
+// generic 将 v 转换为 JSON 对应的通用结构，供 go-template 使用
+func generic(v any) (any, error) {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+	var out any
+	err = json.Unmarshal(data, &out)
+	return out, err
+}
+
+type templatePrinter struct {
+	tmpl *template.Template
+}
+
+// NewTemplatePrinter 创建 go-template Printer，模板作用于值的 JSON 形式，
+// 因此字段名与 json 标签一致，例如 {{.metadata.name}}
+func NewTemplatePrinter(text string) (Printer, error) {
+	tmpl, err := template.New("output").Option("missingkey=error").Parse(text)
+	if err != nil {
+		return nil, fmt.Errorf("invalid go-template: %w", err)
+	}
+	return &templatePrinter{tmpl: tmpl}, nil
+}
+
+func (p *templatePrinter) Print(w io.Writer, v any) error {
+	data, err := generic(v)
+	if err != nil {
+		return err
+	}
+	return p.tmpl.Execute(w, data)
+}
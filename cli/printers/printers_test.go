@@ -0,0 +1,106 @@
+package printers
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/chhz0/goose/meta"
+	"github.com/spf13/pflag"
+)
+
+type testMeta struct {
+	Name string `json:"name"`
+}
+
+type testItem struct {
+	testMeta `json:",inline"`
+	Port     int               `json:"port" table:"PORT"`
+	Node     string            `json:"node" table:"NODE,wide"`
+	Labels   map[string]string `json:"labels,omitempty"`
+	Secret   string            `json:"-" table:"-"`
+}
+
+var testItems = []testItem{
+	{testMeta: testMeta{Name: "web"}, Port: 80, Node: "n1", Labels: map[string]string{"app": "web", "env": "prod"}},
+	{testMeta: testMeta{Name: "db"}, Port: 5432, Node: "n2", Secret: "x"},
+}
+
+func TestPrinters(t *testing.T) {
+	tests := []struct {
+		output    string
+		noHeaders bool
+		v         any
+		want      string
+	}{
+		{output: "table", v: testItems, want: "NAME   PORT   LABELS\nweb    80     app=web,env=prod\ndb     5432   \n"},
+		{output: "wide", v: &testItems[1], want: "NAME   PORT   NODE   LABELS\ndb     5432   n2     \n"},
+		{output: "table", noHeaders: true, v: []string{"a", "b"}, want: "a\nb\n"},
+		{output: "json", v: testItems[1], want: "{\n  \"name\": \"db\",\n  \"port\": 5432,\n  \"node\": \"n2\"\n}\n"},
+		{output: "yaml", v: testItems[:1], want: "- name: web\n  port: 80\n  node: n1\n  labels:\n    app: web\n    env: prod\n"},
+		{output: "jsonpath={[*].name}", v: testItems, want: "web db"},
+		{output: `jsonpath={[0].labels.app}:{[-1].port}\n`, v: testItems, want: "web:5432\n"},
+		{output: `jsonpath={range [*]}{.name}{"\t"}{.port}{"\n"}{end}`, v: testItems, want: "web\t80\ndb\t5432\n"},
+		{output: `jsonpath={[?(@.port==5432)].name}`, v: testItems, want: "db"},
+		{output: `jsonpath={.labels['app']}`, v: testItems[0], want: "web"},
+		{output: "go-template={{range .}}{{.name}}={{.port}};{{end}}", v: testItems, want: "web=80;db=5432;"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.output, func(t *testing.T) {
+			p, err := NewPrinter(tt.output, meta.TableOptions{NoHeaders: tt.noHeaders})
+			if err != nil {
+				t.Fatal(err)
+			}
+			var buf bytes.Buffer
+			if err := p.Print(&buf, tt.v); err != nil {
+				t.Fatal(err)
+			}
+			if buf.String() != tt.want {
+				t.Errorf("Print() = %q, want %q", buf.String(), tt.want)
+			}
+		})
+	}
+}
+
+func TestPrinters_Errors(t *testing.T) {
+	for _, output := range []string{"xml", "json=x", "jsonpath", "jsonpath={.a", "go-template={{.a", "go-template-file=missing.tmpl"} {
+		if _, err := NewPrinter(output, meta.TableOptions{}); err == nil {
+			t.Errorf("NewPrinter(%q) returned nil error", output)
+		}
+	}
+
+	for _, output := range []string{"jsonpath={.missing}", "jsonpath={['missing']}", "jsonpath={range [*]}{.missing}{end}"} {
+		p, err := NewPrinter(output, meta.TableOptions{})
+		if err != nil {
+			t.Fatal(err)
+		}
+		var buf bytes.Buffer
+		if err := p.Print(&buf, testItems); err == nil || !strings.Contains(err.Error(), "missing is not found") {
+			t.Errorf("%s: Print() error = %v, want not found", output, err)
+		}
+		if buf.Len() != 0 {
+			t.Errorf("%s: printed %q before the error", output, buf.String())
+		}
+	}
+}
+
+func TestPrintFlags(t *testing.T) {
+	pf := NewPrintFlags(Table)
+	fs := pflag.NewFlagSet("test", pflag.ContinueOnError)
+	pf.AddFlags(fs)
+
+	if err := fs.Parse([]string{"-o", "xml"}); err == nil {
+		t.Error("Parse(-o xml) returned nil error")
+	}
+	if err := fs.Parse([]string{"-o", "wide", "--no-headers"}); err != nil {
+		t.Fatal(err)
+	}
+
+	var buf bytes.Buffer
+	if err := pf.Print(&buf, testItems[1]); err != nil {
+		t.Fatal(err)
+	}
+	if want := "db   5432   n2   \n"; buf.String() != want {
+		t.Errorf("Print() = %q, want %q", buf.String(), want)
+	}
+}
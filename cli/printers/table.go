@@ -0,0 +1,171 @@
+package printers
+
+import (
+	"fmt"
+	"io"
+	"reflect"
+	"sort"
+	"strings"
+	"text/tabwriter"
+	"time"
+)
+
+// TablePrinter 以表格形式输出结构体或结构体切片，列由字段的 table 标签决定:
+//
+//	table:"NAME"       列名，未设置标签时使用大写的字段名
+//	table:"NODE,wide"  仅在 -o wide 时显示
+//	table:"-"          忽略该字段
+//
+// 匿名嵌入的结构体字段会被展开；非结构体的值输出为单列 VALUE
+type TablePrinter struct {
+	NoHeaders bool
+	Wide      bool
+}
+
+type column struct {
+	header string
+	index  []int
+}
+
+func (p *TablePrinter) Print(w io.Writer, v any) error {
+	rows := reflect.ValueOf(v)
+	for rows.Kind() == reflect.Pointer || rows.Kind() == reflect.Interface {
+		if rows.IsNil() {
+			return nil
+		}
+		rows = rows.Elem()
+	}
+	if rows.Kind() != reflect.Slice && rows.Kind() != reflect.Array {
+		rows = reflect.Append(reflect.MakeSlice(reflect.SliceOf(rows.Type()), 0, 1), rows)
+	}
+
+	elem := rows.Type().Elem()
+	for elem.Kind() == reflect.Pointer {
+		elem = elem.Elem()
+	}
+	var columns []column
+	if elem.Kind() == reflect.Struct && elem != reflect.TypeOf(time.Time{}) {
+		columns = p.columns(elem, nil)
+	}
+
+	tw := tabwriter.NewWriter(w, 0, 4, 3, ' ', 0)
+	if !p.NoHeaders {
+		if columns == nil {
+			fmt.Fprintln(tw, "VALUE")
+		} else {
+			headers := make([]string, 0, len(columns))
+			for _, c := range columns {
+				headers = append(headers, c.header)
+			}
+			fmt.Fprintln(tw, strings.Join(headers, "\t"))
+		}
+	}
+	for i := 0; i < rows.Len(); i++ {
+		row := rows.Index(i)
+		for row.Kind() == reflect.Pointer || row.Kind() == reflect.Interface {
+			if row.IsNil() {
+				break
+			}
+			row = row.Elem()
+		}
+		if columns == nil {
+			fmt.Fprintln(tw, cell(row))
+			continue
+		}
+
+		cells := make([]string, 0, len(columns))
+		for _, c := range columns {
+			cells = append(cells, cell(fieldByIndex(row, c.index)))
+		}
+		fmt.Fprintln(tw, strings.Join(cells, "\t"))
+	}
+	return tw.Flush()
+}
+
+func (p *TablePrinter) columns(t reflect.Type, index []int) []column {
+	var columns []column
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		if !field.IsExported() && !field.Anonymous {
+			continue
+		}
+		idx := append(append([]int(nil), index...), i)
+
+		tag, hasTag := field.Tag.Lookup("table")
+		if tag == "-" {
+			continue
+		}
+		if field.Anonymous && !hasTag && field.Type.Kind() == reflect.Struct {
+			columns = append(columns, p.columns(field.Type, idx)...)
+			continue
+		}
+		if !field.IsExported() {
+			continue
+		}
+
+		name, opts, _ := strings.Cut(tag, ",")
+		if opts == "wide" && !p.Wide {
+			continue
+		}
+		if name == "" {
+			name = strings.ToUpper(field.Name)
+		}
+		columns = append(columns, column{header: name, index: idx})
+	}
+	return columns
+}
+
+func fieldByIndex(v reflect.Value, index []int) reflect.Value {
+	for _, i := range index {
+		if v.Kind() == reflect.Pointer {
+			if v.IsNil() {
+				return reflect.Value{}
+			}
+			v = v.Elem()
+		}
+		if v.Kind() != reflect.Struct {
+			return reflect.Value{}
+		}
+		v = v.Field(i)
+	}
+	return v
+}
+
+// cell 格式化单元格，空指针显示为 <none>
+func cell(v reflect.Value) string {
+	if !v.IsValid() {
+		return "<none>"
+	}
+	if (v.Kind() == reflect.Pointer || v.Kind() == reflect.Interface) && v.IsNil() {
+		return "<none>"
+	}
+
+	switch t := v.Interface().(type) {
+	case time.Time:
+		if t.IsZero() {
+			return "<none>"
+		}
+		return t.Format(time.RFC3339)
+	case fmt.Stringer:
+		return t.String()
+	}
+
+	v = reflect.Indirect(v)
+	switch v.Kind() {
+	case reflect.Slice, reflect.Array:
+		items := make([]string, 0, v.Len())
+		for i := 0; i < v.Len(); i++ {
+			items = append(items, cell(v.Index(i)))
+		}
+		return strings.Join(items, ",")
+	case reflect.Map:
+		keys := make([]string, 0, v.Len())
+		for _, k := range v.MapKeys() {
+			keys = append(keys, fmt.Sprintf("%v=%s", k.Interface(), cell(v.MapIndex(k))))
+		}
+		sort.Strings(keys)
+		return strings.Join(keys, ",")
+	default:
+		return fmt.Sprint(v.Interface())
+	}
+}
@@ -0,0 +1,248 @@
+package cli
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+	"golang.org/x/term"
+)
+
+const (
+	yesFlagName     = "yes"
+	noInputFlagName = "no-input"
+)
+
+// Prompter 向用户询问输入，通过 WithPrompter 替换，便于测试
+type Prompter interface {
+	// Input 读取一行输入，secret 为 true 时不回显
+	Input(label string, secret bool) (string, error)
+	// Select 从 options 中选择一项
+	Select(label string, options []string) (string, error)
+	// Confirm 询问 y/N，默认为 N
+	Confirm(label string) (bool, error)
+}
+
+type prompter struct {
+	in  io.Reader
+	r   *bufio.Reader
+	out io.Writer
+}
+
+// NewPrompter 返回基于 in/out 的 Prompter，in 为终端时 secret 输入不回显
+func NewPrompter(in io.Reader, out io.Writer) Prompter {
+	return &prompter{in: in, r: bufio.NewReader(in), out: out}
+}
+
+func (p *prompter) readLine() (string, error) {
+	line, err := p.r.ReadString('\n')
+	if err != nil && (!errors.Is(err, io.EOF) || line == "") {
+		return "", err
+	}
+	return strings.TrimRight(line, "\r\n"), nil
+}
+
+func (p *prompter) Input(label string, secret bool) (string, error) {
+	fmt.Fprintf(p.out, "%s: ", label)
+	if f, ok := p.in.(*os.File); ok && secret && term.IsTerminal(int(f.Fd())) {
+		data, err := term.ReadPassword(int(f.Fd()))
+		fmt.Fprintln(p.out)
+		return string(data), err
+	}
+	return p.readLine()
+}
+
+func (p *prompter) Select(label string, options []string) (string, error) {
+	fmt.Fprintf(p.out, "%s:\n", label)
+	for i, opt := range options {
+		fmt.Fprintf(p.out, "  %d) %s\n", i+1, opt)
+	}
+	fmt.Fprintf(p.out, "Enter a number (1-%d): ", len(options))
+
+	answer, err := p.readLine()
+	if err != nil {
+		return "", err
+	}
+	answer = strings.TrimSpace(answer)
+	if n, err := strconv.Atoi(answer); err == nil && n >= 1 && n <= len(options) {
+		return options[n-1], nil
+	}
+	if slices.Contains(options, answer) {
+		return answer, nil
+	}
+	return "", fmt.Errorf("invalid selection %q", answer)
+}
+
+func (p *prompter) Confirm(label string) (bool, error) {
+	fmt.Fprintf(p.out, "%s [y/N]: ", label)
+	answer, err := p.readLine()
+	if err != nil {
+		return false, err
+	}
+	switch strings.ToLower(strings.TrimSpace(answer)) {
+	case "y", "yes":
+		return true, nil
+	default:
+		return false, nil
+	}
+}
+
+// ErrNotConfirmed 用户拒绝了 Confirm 的确认
+var ErrNotConfirmed = errors.New("operation not confirmed")
+
+// needsPrompt 判断命令树中是否有命令声明了 PromptFlags 或 Confirm
+func (cb *commandBuilder) needsPrompt() bool {
+	found := false
+	cb.walk(func(b *commandBuilder) {
+		ic := optional[InteractiveCommander](b.commander)
+		found = found || len(ic.PromptableFlags()) > 0 || ic.ConfirmMsg() != ""
+	})
+	return found
+}
+
+// addPromptFlags 在根命令上添加 --yes 与 --no-input，命令树中已有同名 flag 时返回错误，
+// -y 已被其他 flag 使用时 --yes 不设置简写
+func addPromptFlags(root *cobra.Command) error {
+	names := make(map[string]string)
+	shorthands := make(map[string]bool)
+	var visit func(c *cobra.Command)
+	visit = func(c *cobra.Command) {
+		for _, fs := range []*pflag.FlagSet{c.Flags(), c.PersistentFlags()} {
+			fs.VisitAll(func(f *pflag.Flag) {
+				names[f.Name] = c.CommandPath()
+				if f.Shorthand != "" {
+					shorthands[f.Shorthand] = true
+				}
+			})
+		}
+		for _, sub := range c.Commands() {
+			visit(sub)
+		}
+	}
+	visit(root)
+
+	for _, name := range []string{yesFlagName, noInputFlagName} {
+		if path, ok := names[name]; ok {
+			return fmt.Errorf("command %q: flag --%s is reserved for PromptFlags and Confirm", path, name)
+		}
+	}
+	short := "y"
+	if shorthands[short] {
+		short = ""
+	}
+
+	fs := root.PersistentFlags()
+	fs.BoolP(yesFlagName, short, false, "assume yes for confirmations and fail instead of prompting")
+	fs.Bool(noInputFlagName, false, "never prompt for input, fail if input is required")
+	return nil
+}
+
+// interactivePrompter 返回可用于交互的 Prompter，--yes、--no-input 或标准输入不是终端时返回 nil
+func (cb *commandBuilder) interactivePrompter(cmd *cobra.Command) Prompter {
+	if yes, _ := cmd.Flags().GetBool(yesFlagName); yes {
+		return nil
+	}
+	if noInput, _ := cmd.Flags().GetBool(noInputFlagName); noInput {
+		return nil
+	}
+	if cb.prompter != nil {
+		return cb.prompter
+	}
+	if f, ok := cmd.InOrStdin().(*os.File); ok && term.IsTerminal(int(f.Fd())) {
+		return NewPrompter(f, cmd.ErrOrStderr())
+	}
+	return nil
+}
+
+// promptFlags 为未指定的 PromptFlags 询问输入，无法交互时返回用法错误
+func (cb *commandBuilder) promptFlags(cmd *cobra.Command) error {
+	var missing []*pflag.Flag
+	for _, name := range optional[InteractiveCommander](cb.commander).PromptableFlags() {
+		f := cmd.Flags().Lookup(name)
+		if f == nil {
+			return fmt.Errorf("prompt flag %q is not defined", name)
+		}
+		if !f.Changed {
+			missing = append(missing, f)
+		}
+	}
+	if len(missing) == 0 {
+		return nil
+	}
+
+	p := cb.interactivePrompter(cmd)
+	if p == nil {
+		names := make([]string, 0, len(missing))
+		for _, f := range missing {
+			names = append(names, `"`+f.Name+`"`)
+		}
+		return &UsageError{
+			Cmd: cmd.CommandPath(),
+			Err: fmt.Errorf("required flag(s) %s not set", strings.Join(names, ", ")),
+		}
+	}
+
+	for _, f := range missing {
+		val, err := promptFlag(p, f)
+		if err != nil {
+			return err
+		}
+		if err := cmd.Flags().Set(f.Name, val); err != nil {
+			return &UsageError{Cmd: cmd.CommandPath(), Err: fmt.Errorf("invalid value for --%s: %w", f.Name, err)}
+		}
+	}
+	return nil
+}
+
+func promptFlag(p Prompter, f *pflag.Flag) (string, error) {
+	label := f.Name
+	if f.Usage != "" {
+		label = f.Usage + " (--" + f.Name + ")"
+	}
+
+	switch v := f.Value.(type) {
+	case *enumValue:
+		return p.Select(label, v.allowed)
+	case *secretValue:
+		return p.Input(label, true)
+	}
+	if f.Value.Type() == "bool" {
+		ok, err := p.Confirm(label)
+		return strconv.FormatBool(ok), err
+	}
+	return p.Input(label, false)
+}
+
+// confirm 在执行带 Confirm 的命令前请求确认，--yes 跳过确认，无法交互时返回用法错误
+func (cb *commandBuilder) confirm(cmd *cobra.Command) error {
+	msg := optional[InteractiveCommander](cb.commander).ConfirmMsg()
+	if msg == "" {
+		return nil
+	}
+	if yes, _ := cmd.Flags().GetBool(yesFlagName); yes {
+		return nil
+	}
+
+	p := cb.interactivePrompter(cmd)
+	if p == nil {
+		return &UsageError{
+			Cmd: cmd.CommandPath(),
+			Err: fmt.Errorf("%s requires confirmation, use --%s to confirm", cmd.CommandPath(), yesFlagName),
+		}
+	}
+	ok, err := p.Confirm(msg)
+	if err != nil {
+		return err
+	}
+	if !ok {
+		return ErrNotConfirmed
+	}
+	return nil
+}
@@ -0,0 +1,171 @@
+package cli
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/spf13/pflag"
+)
+
+func TestCommand_Prompt(t *testing.T) {
+	type result struct {
+		name, format, token string
+		ran                 bool
+	}
+	newRoot := func(got *result) Commander {
+		return &Command{
+			Use: "app",
+			Commands: []Commander{
+				&Command{
+					Use: "create",
+					FlagSet: &FlagSet{Local: func(pfs *pflag.FlagSet) {
+						pfs.StringVar(&got.name, "name", "", "resource name")
+						pfs.Var(NewEnumValue(&got.format, "json", "yaml"), "format", "")
+						pfs.Var(NewSecretValue(&got.token), "token", "")
+					}},
+					PromptFlags: []string{"name", "format", "token"},
+					Run: func(ctx context.Context, args []string) error {
+						got.ran = true
+						return nil
+					},
+				},
+				&Command{
+					Use:     "delete",
+					Confirm: "Delete all resources?",
+					Run: func(ctx context.Context, args []string) error {
+						got.ran = true
+						return nil
+					},
+				},
+			},
+		}
+	}
+
+	tests := []struct {
+		name       string
+		args       []string
+		input      string
+		noPrompter bool
+		want       result
+		wantErr    string
+		wantOut    string
+	}{
+		{
+			name: "prompt missing", args: []string{"create", "--name", "web"}, input: "2\ns3cret\n",
+			want:    result{name: "web", format: "yaml", token: "s3cret", ran: true},
+			wantOut: "format:\n  1) json\n  2) yaml\nEnter a number (1-2): token: ",
+		},
+		{name: "invalid selection", args: []string{"create"}, input: "web\n3\n", wantErr: `invalid selection "3"`},
+		{name: "no input", args: []string{"create", "--no-input", "--format", "json"}, wantErr: `required flag(s) "name", "token" not set`},
+		{name: "not a terminal", args: []string{"create", "--name", "a", "--token", "t"}, noPrompter: true, wantErr: `required flag(s) "format" not set`},
+		{name: "confirm", args: []string{"delete"}, input: "y\n", want: result{ran: true}, wantOut: "Delete all resources? [y/N]: "},
+		{name: "declined", args: []string{"delete"}, input: "\n", wantErr: ErrNotConfirmed.Error()},
+		{name: "yes", args: []string{"delete", "-y"}, want: result{ran: true}},
+		{name: "confirm without terminal", args: []string{"delete"}, noPrompter: true, wantErr: "use --yes to confirm"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got result
+			var out bytes.Buffer
+			opts := []Option{WithArgs(tt.args...), WithIOStreams(IOStreams{In: strings.NewReader(tt.input)})}
+			if !tt.noPrompter {
+				opts = append(opts, WithPrompter(NewPrompter(strings.NewReader(tt.input), &out)))
+			}
+			exec, err := NewCommand(newRoot(&got), opts...)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			err = exec.Execute(context.Background())
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("Execute() error = %v, want %q", err, tt.wantErr)
+				}
+				if got.ran {
+					t.Error("command ran after failed prompt")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got != tt.want {
+				t.Errorf("got %+v, want %+v", got, tt.want)
+			}
+			if out.String() != tt.wantOut {
+				t.Errorf("prompt output = %q, want %q", out.String(), tt.wantOut)
+			}
+		})
+	}
+}
+
+func TestCommand_PromptUsageError(t *testing.T) {
+	exec, err := NewCommand(&Command{
+		Use:         "app",
+		FlagSet:     &FlagSet{Local: func(pfs *pflag.FlagSet) { pfs.String("name", "", "") }},
+		PromptFlags: []string{"name"},
+		Run:         func(ctx context.Context, args []string) error { return nil },
+	}, WithArgs("--no-input"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var usageErr *UsageError
+	if err := exec.Execute(context.Background()); !errors.As(err, &usageErr) {
+		t.Errorf("Execute() error = %v, want UsageError", err)
+	}
+}
+
+func TestCommand_PromptFlagConflicts(t *testing.T) {
+	var year int
+	var deleted bool
+	newRoot := func(flags func(pfs *pflag.FlagSet)) Commander {
+		return &Command{
+			Use: "app",
+			Commands: []Commander{
+				&Command{
+					Use:     "delete",
+					Confirm: "Delete everything?",
+					Run: func(ctx context.Context, args []string) error {
+						deleted = true
+						return nil
+					},
+				},
+				&Command{
+					Use:     "report",
+					FlagSet: &FlagSet{Local: flags},
+					Run:     func(ctx context.Context, args []string) error { return nil },
+				},
+			},
+		}
+	}
+
+	// -y 已被占用时 --yes 不设置简写
+	yearFlag := func(pfs *pflag.FlagSet) { pfs.IntVarP(&year, "year", "y", 2000, "report year") }
+	run := func(args ...string) {
+		exec, err := NewCommand(newRoot(yearFlag), WithArgs(args...))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := exec.Execute(context.Background()); err != nil {
+			t.Fatalf("%v: %v", args, err)
+		}
+	}
+	run("report", "-y", "2024")
+	if year != 2024 {
+		t.Errorf("year = %d, want 2024", year)
+	}
+	run("delete", "--yes")
+	if !deleted {
+		t.Error("delete --yes did not run")
+	}
+
+	for _, name := range []string{"yes", "no-input"} {
+		_, err := NewCommand(newRoot(func(pfs *pflag.FlagSet) { pfs.Bool(name, false, "") }))
+		if err == nil || !strings.Contains(err.Error(), "--"+name) {
+			t.Errorf("flag --%s: NewCommand() error = %v, want conflict", name, err)
+		}
+	}
+}
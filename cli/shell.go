@@ -0,0 +1,448 @@
+package cli
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"slices"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+	"golang.org/x/term"
+)
+
+const shellCmdName = "shell"
+
+// Shell 返回一个交互式执行器，Execute 逐行读取命令并在同一棵命令树上执行，
+// 共享传入 Execute 的 context。根命令的 PersistentPreRun 只在会话的第一行运行，
+// PersistentPostRun 与 Teardown 在会话结束时运行，使其中的初始化在会话中保持；
+// 其余命令的生命周期在每一行完整运行。
+// 标准输入为终端时支持历史记录与 Tab 补全，补全与 shell 补全脚本使用同一份元数据；
+// 内置命令: exit、quit、history
+func Shell(root Commander, opts ...Option) (Exec, error) {
+	exec, err := NewCommand(root, opts...)
+	if err != nil {
+		return nil, err
+	}
+	e := exec.(*executor)
+	if !e.flagsPrepared {
+		if err := e.prepareFlags(); err != nil {
+			return nil, err
+		}
+	}
+	return &shellExec{executor: e}, nil
+}
+
+type shellExec struct {
+	*executor
+}
+
+// Execute implements Exec.
+func (s *shellExec) Execute(ctx context.Context) error {
+	return s.shell(ctx)
+}
+
+// Run implements Exec.
+func (s *shellExec) Run(ctx context.Context) int {
+	return s.exit(s.Execute(ctx))
+}
+
+// shellCommand 返回 shell 子命令，由 WithShell 添加到根命令
+func shellCommand() Commander {
+	noArgs := cobra.PositionalArgs(cobra.NoArgs)
+	return &Command{
+		Use:   shellCmdName,
+		Short: "Start an interactive shell.",
+		Long: `Start an interactive shell that keeps the command tree loaded.
+
+Each line is run as a full command. Use "exit" or "quit" to leave the shell
+and "history" to list previous commands.`,
+		Args: &noArgs,
+		// 交互模式在命令执行完成后由 Execute 启动，根命令的 PersistentPostRun 与 Teardown 在会话结束时运行
+		Run: func(ctx context.Context, args []string) error {
+			return nil
+		},
+	}
+}
+
+// session 交互会话的状态，由命令树中的全部 builder 共享
+type session struct {
+	shellCmd *cobra.Command
+	active   bool
+	// started 表示根命令的 PersistentPreRun 已在会话中运行
+	started bool
+}
+
+// rootStarted 判断根命令的钩子是否由会话管理，为 true 时每一行跳过根命令的钩子
+func (s *session) rootStarted() bool {
+	return s != nil && s.active && s.started
+}
+
+// begin 在命令执行前调用，执行 shell 子命令时开始会话
+func (s *session) begin(cmd *cobra.Command) {
+	if s != nil && s.shellCmd != nil && cmd == s.shellCmd {
+		s.active = true
+	}
+}
+
+// endSession 结束会话，根命令的 PersistentPreRun 运行过时依次运行其 PersistentPostRun 与 Teardown
+func (e *executor) endSession(ctx context.Context, err error) error {
+	s := e.session
+	started := s.started
+	s.active, s.started = false, false
+	if !started {
+		return err
+	}
+
+	ctx = e.ioContext(ctx)
+	hooks := optional[HookedCommander](e.builders[e.cobra].commander)
+	if err == nil {
+		err = hooks.PersistentPostFunc(ctx, nil)
+	}
+	if terr := hooks.TeardownFunc(ctx, err); terr != nil {
+		return errors.Join(err, terr)
+	}
+	return err
+}
+
+// shell 运行交互式会话，直到输入结束、exit/quit 或 ctx 被取消
+func (e *executor) shell(ctx context.Context) (err error) {
+	if e.inShell {
+		return errors.New("already in an interactive shell")
+	}
+	e.inShell = true
+	e.session.active = true
+	defer func() {
+		e.inShell = false
+		err = e.endSession(ctx, err)
+	}()
+
+	lr := e.newLineReader(ctx)
+	var history []string
+	for {
+		line, err := lr.readLine()
+		if errors.Is(err, io.EOF) {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+
+		args, err := splitLine(line)
+		if err != nil {
+			e.printError(e.cobra.ErrOrStderr(), err, ExitUsage)
+			continue
+		}
+		if len(args) == 0 {
+			continue
+		}
+		history = append(history, line)
+
+		switch args[0] {
+		case "exit", "quit":
+			return nil
+		case "history":
+			for i, h := range history {
+				fmt.Fprintf(e.cobra.OutOrStdout(), "%5d  %s\n", i+1, h)
+			}
+			continue
+		}
+
+		if err := e.reset(); err != nil {
+			e.printError(e.cobra.ErrOrStderr(), err, ExitFailure)
+			continue
+		}
+		e.cobra.SetArgs(args)
+		if _, err := e.execute(ctx); err != nil {
+			e.printError(e.cobra.ErrOrStderr(), err, ExitCode(err))
+		}
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+	}
+}
+
+// prepareFlags 使命令树中的 flag 可以在 shell 的两行之间恢复默认值:
+// pflag 的切片类型由 shellSliceValue 包装；pflag 内置的 map 类型无法恢复，需要使用 NewStringMapValue
+func (e *executor) prepareFlags() error {
+	var errs []error
+	var visit func(c *cobra.Command)
+	visit = func(c *cobra.Command) {
+		for _, fs := range []*pflag.FlagSet{c.Flags(), c.PersistentFlags()} {
+			fs.VisitAll(func(f *pflag.Flag) {
+				if err := prepareFlag(f); err != nil {
+					errs = append(errs, fmt.Errorf("command %q: flag --%s: %w", c.Name(), f.Name, err))
+				}
+			})
+		}
+		for _, sub := range c.Commands() {
+			visit(sub)
+		}
+	}
+	visit(e.cobra)
+	e.flagsPrepared = true
+	return errors.Join(errs...)
+}
+
+func prepareFlag(f *pflag.Flag) error {
+	if _, ok := f.Value.(interface{ reset() }); ok {
+		return nil
+	}
+	if sv, ok := f.Value.(sliceValue); ok {
+		f.Value = &shellSliceValue{sliceValue: sv, def: slices.Clone(sv.GetSlice())}
+		return nil
+	}
+	return checkMapFlag(f)
+}
+
+// checkMapFlag pflag 内置的 map 类型在设置后只会合并，无法恢复默认值
+func checkMapFlag(f *pflag.Flag) error {
+	if strings.HasPrefix(f.Value.Type(), "stringTo") {
+		return fmt.Errorf("%s values cannot be reset in a shell, use NewStringMapValue", f.Value.Type())
+	}
+	return nil
+}
+
+type sliceValue interface {
+	pflag.Value
+	pflag.SliceValue
+}
+
+// shellSliceValue 包装 pflag 的切片类型: pflag 在第一次 Set 之后会追加而不是替换，
+// 因此每一行第一次设置时先清空，使之后的值替换默认值
+type shellSliceValue struct {
+	sliceValue
+	def   []string
+	dirty bool
+}
+
+func (v *shellSliceValue) Set(s string) error {
+	if !v.dirty {
+		if err := v.sliceValue.Replace(nil); err != nil {
+			return err
+		}
+		v.dirty = true
+	}
+	return v.sliceValue.Set(s)
+}
+
+func (v *shellSliceValue) reset() {
+	_ = v.sliceValue.Replace(slices.Clone(v.def))
+	v.dirty = false
+}
+
+// reset 将命令树恢复到未执行的状态: flag 恢复为默认值，清除上一次执行的 context
+func (e *executor) reset() error {
+	var errs []error
+	var visit func(c *cobra.Command)
+	visit = func(c *cobra.Command) {
+		c.SetContext(nil)
+		for _, fs := range []*pflag.FlagSet{c.Flags(), c.PersistentFlags()} {
+			fs.VisitAll(func(f *pflag.Flag) {
+				if err := resetFlag(f); err != nil {
+					errs = append(errs, fmt.Errorf("reset flag --%s: %w", f.Name, err))
+				}
+			})
+		}
+		for _, sub := range c.Commands() {
+			visit(sub)
+		}
+	}
+	visit(e.cobra)
+	return errors.Join(errs...)
+}
+
+// resetFlag 依次尝试: 值类型的 reset 方法、以 DefValue 替换切片、以 DefValue 调用 Set
+func resetFlag(f *pflag.Flag) error {
+	if !f.Changed {
+		// 构建之后才添加的 flag 在未使用时完成包装
+		return prepareFlag(f)
+	}
+	f.Changed = false
+
+	if r, ok := f.Value.(interface{ reset() }); ok {
+		r.reset()
+		return nil
+	}
+	if sv, ok := f.Value.(pflag.SliceValue); ok {
+		var def []string
+		if s := strings.Trim(f.DefValue, "[]"); s != "" {
+			def = strings.Split(s, ",")
+		}
+		return sv.Replace(def)
+	}
+	if err := checkMapFlag(f); err != nil {
+		return err
+	}
+	return f.Value.Set(f.DefValue)
+}
+
+// complete 通过 cobra 的 __complete 命令获取补全候选项
+func (e *executor) complete(ctx context.Context, args []string, toComplete string) []string {
+	var buf bytes.Buffer
+	out, errOut := e.cobra.OutOrStdout(), e.cobra.ErrOrStderr()
+	e.cobra.SetOut(&buf)
+	e.cobra.SetErr(io.Discard)
+	defer func() {
+		e.cobra.SetOut(out)
+		e.cobra.SetErr(errOut)
+		for _, c := range e.cobra.Commands() {
+			if c.Name() == cobra.ShellCompRequestCmd {
+				e.cobra.RemoveCommand(c)
+			}
+		}
+	}()
+
+	if err := e.reset(); err != nil {
+		return nil
+	}
+	e.cobra.SetArgs(append(append([]string{cobra.ShellCompNoDescRequestCmd}, args...), toComplete))
+	if _, err := e.cobra.ExecuteContextC(ctx); err != nil {
+		return nil
+	}
+
+	var candidates []string
+	for _, line := range strings.Split(strings.TrimSpace(buf.String()), "\n") {
+		if line == "" || strings.HasPrefix(line, ":") {
+			continue
+		}
+		candidates = append(candidates, line)
+	}
+	return candidates
+}
+
+// autoComplete 实现 term.Terminal 的 AutoCompleteCallback，只处理 Tab
+func (e *executor) autoComplete(ctx context.Context, w io.Writer) func(line string, pos int, key rune) (string, int, bool) {
+	return func(line string, pos int, key rune) (string, int, bool) {
+		if key != '\t' {
+			return "", 0, false
+		}
+
+		head := line[:pos]
+		args, err := splitLine(head)
+		if err != nil {
+			return "", 0, false
+		}
+		toComplete := ""
+		if len(args) > 0 && !strings.HasSuffix(head, " ") {
+			toComplete = args[len(args)-1]
+			args = args[:len(args)-1]
+		}
+
+		candidates := e.complete(ctx, args, toComplete)
+		var completed string
+		switch {
+		case len(candidates) == 0:
+			return "", 0, false
+		case len(candidates) == 1:
+			completed = candidates[0] + " "
+		default:
+			completed = commonPrefix(candidates)
+			if len(completed) <= len(toComplete) {
+				fmt.Fprintln(w, strings.Join(candidates, "  "))
+				return "", 0, false
+			}
+		}
+
+		head = strings.TrimSuffix(head, toComplete) + completed
+		return head + line[pos:], len(head), true
+	}
+}
+
+func commonPrefix(ss []string) string {
+	prefix := ss[0]
+	for _, s := range ss[1:] {
+		for !strings.HasPrefix(s, prefix) {
+			prefix = prefix[:len(prefix)-1]
+		}
+	}
+	return prefix
+}
+
+type lineReader struct {
+	readLine func() (string, error)
+}
+
+// newLineReader 标准输入为终端时使用 term.Terminal，否则按行读取且不输出提示符
+func (e *executor) newLineReader(ctx context.Context) *lineReader {
+	in, out := e.cobra.InOrStdin(), e.cobra.OutOrStdout()
+
+	f, ok := in.(*os.File)
+	if !ok || !term.IsTerminal(int(f.Fd())) {
+		scanner := bufio.NewScanner(in)
+		return &lineReader{readLine: func() (string, error) {
+			if scanner.Scan() {
+				return scanner.Text(), nil
+			}
+			if err := scanner.Err(); err != nil {
+				return "", err
+			}
+			return "", io.EOF
+		}}
+	}
+
+	t := term.NewTerminal(struct {
+		io.Reader
+		io.Writer
+	}{in, out}, e.cobra.Name()+"> ")
+	t.AutoCompleteCallback = e.autoComplete(ctx, t)
+	fd := int(f.Fd())
+	return &lineReader{readLine: func() (string, error) {
+		// 仅在读取时进入 raw 模式，命令执行期间恢复终端，使输出与信号处理保持正常
+		state, err := term.MakeRaw(fd)
+		if err != nil {
+			return "", err
+		}
+		defer func() { _ = term.Restore(fd, state) }()
+		return t.ReadLine()
+	}}
+}
+
+// splitLine 按 shell 的规则切分一行输入，支持单引号、双引号与反斜杠转义
+func splitLine(line string) ([]string, error) {
+	var (
+		args    []string
+		cur     strings.Builder
+		inToken bool
+		quote   rune
+		escaped bool
+	)
+	for _, r := range line {
+		switch {
+		case escaped:
+			cur.WriteRune(r)
+			escaped = false
+		case r == '\\' && quote != '\'':
+			escaped, inToken = true, true
+		case quote != 0:
+			if r == quote {
+				quote = 0
+			} else {
+				cur.WriteRune(r)
+			}
+		case r == '\'' || r == '"':
+			quote, inToken = r, true
+		case r == ' ' || r == '\t':
+			if inToken {
+				args = append(args, cur.String())
+				cur.Reset()
+				inToken = false
+			}
+		default:
+			cur.WriteRune(r)
+			inToken = true
+		}
+	}
+	if quote != 0 || escaped {
+		return nil, fmt.Errorf("unterminated quote or escape in %q", line)
+	}
+	if inToken {
+		args = append(args, cur.String())
+	}
+	return args, nil
+}
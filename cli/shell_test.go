@@ -0,0 +1,246 @@
+package cli
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"maps"
+	"reflect"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/spf13/pflag"
+)
+
+func TestSplitLine(t *testing.T) {
+	tests := []struct {
+		line    string
+		want    []string
+		wantErr bool
+	}{
+		{line: "", want: nil},
+		{line: "  greet   --name bob ", want: []string{"greet", "--name", "bob"}},
+		{line: `say "hello world" 'it''s'`, want: []string{"say", "hello world", "its"}},
+		{line: `say a\ b "q\"" ''`, want: []string{"say", "a b", `q"`, ""}},
+		{line: `say "open`, wantErr: true},
+	}
+	for _, tt := range tests {
+		got, err := splitLine(tt.line)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("splitLine(%q) error = %v, wantErr %v", tt.line, err, tt.wantErr)
+			continue
+		}
+		if !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("splitLine(%q) = %q, want %q", tt.line, got, tt.want)
+		}
+	}
+}
+
+func newShellRoot(inits *int) Commander {
+	opts := &struct {
+		Name string   `flag:"name" default:"world"`
+		Tags []string `flag:"tag"`
+	}{}
+	return &Command{
+		Use: "app",
+		PersistentPreRun: func(ctx context.Context, args []string) error {
+			*inits++
+			return nil
+		},
+		Commands: []Commander{
+			&Command{
+				Use:     "greet",
+				FlagSet: FlagsFromStruct(opts),
+				Run: func(ctx context.Context, args []string) error {
+					fmt.Fprintf(IO(ctx).Out, "hello %s %v\n", opts.Name, opts.Tags)
+					return nil
+				},
+			},
+			&Command{Use: "gremlin"},
+			&Command{Use: "other"},
+		},
+	}
+}
+
+func TestShell(t *testing.T) {
+	input := strings.Join([]string{
+		"greet --name bob --tag a --tag b",
+		"greet",
+		"nope",
+		"",
+		"history",
+		"exit",
+		"greet",
+	}, "\n")
+
+	var inits, posts int
+	var teardowns []int
+	var stdout, stderr bytes.Buffer
+	root := newShellRoot(&inits).(*Command)
+	root.PersistentPostRun = func(ctx context.Context, args []string) error {
+		posts++
+		return nil
+	}
+	root.Teardown = func(ctx context.Context, err error) error {
+		teardowns = append(teardowns, strings.Count(stdout.String(), "\n"))
+		return err
+	}
+	exec, err := Shell(root, WithIOStreams(IOStreams{
+		In: strings.NewReader(input), Out: &stdout, ErrOut: &stderr,
+	}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if code := exec.Run(context.Background()); code != ExitOK {
+		t.Fatalf("Run() = %d, stderr = %q", code, stderr.String())
+	}
+
+	want := "hello bob [a b]\nhello world []\n" +
+		"    1  greet --name bob --tag a --tag b\n    2  greet\n    3  nope\n    4  history\n"
+	if stdout.String() != want {
+		t.Errorf("stdout = %q, want %q", stdout.String(), want)
+	}
+	if !strings.Contains(stderr.String(), `unknown command "nope"`) {
+		t.Errorf("stderr = %q, want unknown command", stderr.String())
+	}
+	// 根命令的钩子在会话中只运行一次，Teardown 在全部输出之后运行
+	if inits != 1 || posts != 1 {
+		t.Errorf("root PersistentPreRun ran %d times, PersistentPostRun ran %d times, want 1", inits, posts)
+	}
+	if !reflect.DeepEqual(teardowns, []int{6}) {
+		t.Errorf("root Teardown ran after lines %v, want [6]", teardowns)
+	}
+}
+
+func TestShell_Subcommand(t *testing.T) {
+	var inits int
+	var stdout bytes.Buffer
+	exec, err := NewCommand(newShellRoot(&inits), WithShell(), WithArgs("shell"), WithIOStreams(IOStreams{
+		In: strings.NewReader("greet --name x\nshell\n"), Out: &stdout, ErrOut: &bytes.Buffer{},
+	}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := exec.Execute(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if stdout.String() != "hello x []\n" {
+		t.Errorf("stdout = %q", stdout.String())
+	}
+	if inits != 1 {
+		t.Errorf("root PersistentPreRun ran %d times, want 1", inits)
+	}
+}
+
+func TestShell_Complete(t *testing.T) {
+	var inits int
+	exec, err := Shell(newShellRoot(&inits), WithIOStreams(IOStreams{Out: &bytes.Buffer{}, ErrOut: &bytes.Buffer{}}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	e := exec.(*shellExec).executor
+	var out bytes.Buffer
+	autoComplete := e.autoComplete(context.Background(), &out)
+
+	tests := []struct {
+		line    string
+		want    string
+		wantOk  bool
+		wantOut string
+	}{
+		{line: "oth", want: "other ", wantOk: true},
+		{line: "gr", want: "gre", wantOk: true},
+		{line: "gre", wantOut: "greet  gremlin\n"},
+		{line: "greet --na", want: "greet --name ", wantOk: true},
+		{line: "zzz"},
+	}
+	for _, tt := range tests {
+		out.Reset()
+		got, pos, ok := autoComplete(tt.line, len(tt.line), '\t')
+		if ok != tt.wantOk || got != tt.want || (ok && pos != len(tt.want)) {
+			t.Errorf("complete(%q) = %q, %d, %v, want %q, %v", tt.line, got, pos, ok, tt.want, tt.wantOk)
+		}
+		if out.String() != tt.wantOut {
+			t.Errorf("complete(%q) printed %q, want %q", tt.line, out.String(), tt.wantOut)
+		}
+	}
+	if inits != 0 {
+		t.Errorf("completion ran PersistentPreRun %d times", inits)
+	}
+}
+
+func TestShell_ResetFlags(t *testing.T) {
+	var (
+		labels  map[string]string
+		count   = 3
+		verbose bool
+		ids     []int
+	)
+	root := &Command{
+		Use: "app",
+		Commands: []Commander{
+			&Command{
+				Use: "show",
+				FlagSet: &FlagSet{Local: func(pfs *pflag.FlagSet) {
+					pfs.Var(NewStringMapValue(&labels), "label", "labels")
+					pfs.IntVar(&count, "count", count, "count")
+					pfs.BoolVar(&verbose, "verbose", false, "verbose")
+					pfs.IntSliceVar(&ids, "id", []int{7}, "ids")
+				}},
+				Run: func(ctx context.Context, args []string) error {
+					keys := slices.Sorted(maps.Keys(labels))
+					fmt.Fprintf(IO(ctx).Out, "%v %d %v %v\n", keys, count, verbose, ids)
+					return nil
+				},
+			},
+		},
+	}
+
+	input := strings.Join([]string{
+		"show --label a=1 --count 5 --verbose --id 1 --id 2",
+		"show",
+		"show --label b=2 --id 3",
+		"show --help",
+		"show",
+	}, "\n")
+	var stdout, stderr bytes.Buffer
+	exec, err := Shell(root, WithIOStreams(IOStreams{In: strings.NewReader(input), Out: &stdout, ErrOut: &stderr}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := exec.Execute(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+
+	lines := strings.Split(strings.TrimSpace(stdout.String()), "\n")
+	want := []string{
+		"[a] 5 true [1 2]",
+		"[] 3 false [7]",
+		"[b] 3 false [3]",
+	}
+	if !reflect.DeepEqual(lines[:3], want) {
+		t.Errorf("stdout = %q, want %q", lines[:3], want)
+	}
+	// --help 只影响当前行
+	if last := lines[len(lines)-1]; last != "[] 3 false [7]" {
+		t.Errorf("line after --help = %q", last)
+	}
+	if stderr.Len() != 0 {
+		t.Errorf("stderr = %q", stderr.String())
+	}
+}
+
+func TestShell_MapFlag(t *testing.T) {
+	var labels map[string]string
+	root := &Command{
+		Use: "app",
+		FlagSet: &FlagSet{Local: func(pfs *pflag.FlagSet) {
+			pfs.StringToStringVar(&labels, "label", nil, "labels")
+		}},
+	}
+	_, err := Shell(root, WithIOStreams(IOStreams{In: strings.NewReader(""), Out: &bytes.Buffer{}}))
+	if err == nil || !strings.Contains(err.Error(), "NewStringMapValue") {
+		t.Errorf("Shell() error = %v, want a hint to use NewStringMapValue", err)
+	}
+}
@@ -0,0 +1,70 @@
+package cli
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/signal"
+	"time"
+)
+
+// exitFunc 强制退出进程，测试中可替换
+var exitFunc = os.Exit
+
+// SignalError 是命令因收到信号而被取消时 context.Cause 返回的错误
+type SignalError struct {
+	Signal os.Signal
+}
+
+func (e *SignalError) Error() string {
+	return fmt.Sprintf("received signal %s", e.Signal)
+}
+
+// Is 使 errors.Is(err, context.Canceled) 对信号取消同样成立
+func (e *SignalError) Is(target error) bool {
+	return target == context.Canceled
+}
+
+// signalContext 返回一个在收到信号时取消的 context，stop 用于释放信号监听
+func (e *executor) signalContext(ctx context.Context) (context.Context, func()) {
+	if len(e.opts.signals) == 0 {
+		return ctx, func() {}
+	}
+
+	ctx, cancel := context.WithCancelCause(ctx)
+	sigCh := make(chan os.Signal, 2)
+	done := make(chan struct{})
+	signal.Notify(sigCh, e.opts.signals...)
+
+	go func() {
+		select {
+		case sig := <-sigCh:
+			cancel(&SignalError{Signal: sig})
+		case <-done:
+			return
+		}
+
+		var timeout <-chan time.Time
+		if e.opts.gracePeriod > 0 {
+			timer := time.NewTimer(e.opts.gracePeriod)
+			defer timer.Stop()
+			timeout = timer.C
+		}
+
+		select {
+		case sig := <-sigCh:
+			fmt.Fprintf(e.cobra.ErrOrStderr(), "received signal %s again, forcing exit\n", sig)
+			exitFunc(ExitForced)
+		case <-timeout:
+			fmt.Fprintf(e.cobra.ErrOrStderr(), "command did not exit within %s, forcing exit\n", e.opts.gracePeriod)
+			exitFunc(ExitForced)
+		case <-done:
+		}
+	}()
+
+	return ctx, func() {
+		close(done)
+		signal.Stop(sigCh)
+		cancel(nil)
+	}
+}
@@ -0,0 +1,56 @@
+//go:build unix
+
+package cli
+
+import (
+	"context"
+	"errors"
+	"syscall"
+	"testing"
+	"time"
+)
+
+func TestExecute_Signals(t *testing.T) {
+	forced := make(chan int, 1)
+	orig := exitFunc
+	exitFunc = func(code int) { forced <- code }
+	t.Cleanup(func() { exitFunc = orig })
+
+	release := make(chan struct{})
+	exec, err := NewCommand(&Command{
+		Use: "app",
+		Run: func(ctx context.Context, args []string) error {
+			_ = syscall.Kill(syscall.Getpid(), syscall.SIGINT)
+			<-ctx.Done()
+
+			var sigErr *SignalError
+			if !errors.As(context.Cause(ctx), &sigErr) || sigErr.Signal != syscall.SIGINT {
+				t.Errorf("cause = %v, want SIGINT", context.Cause(ctx))
+			}
+
+			_ = syscall.Kill(syscall.Getpid(), syscall.SIGINT)
+			<-release
+			return context.Cause(ctx)
+		},
+	}, WithSignals(time.Minute), WithArgs())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	errCh := make(chan error, 1)
+	go func() { errCh <- exec.Execute(context.Background()) }()
+
+	select {
+	case code := <-forced:
+		if code != ExitForced {
+			t.Errorf("exit code = %d, want %d", code, ExitForced)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("second signal did not force exit")
+	}
+
+	close(release)
+	if err := <-errCh; !errors.Is(err, context.Canceled) {
+		t.Errorf("got %v, want context.Canceled", err)
+	}
+}
@@ -0,0 +1,266 @@
+package cli
+
+import (
+	"fmt"
+	"os"
+	"reflect"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+)
+
+const flagEnvAnnotation = "goose_env"
+
+// FlagsFromStruct 根据结构体字段的标签注册 flag，ptr 必须是指向结构体的指针
+//
+//	flag:"name,n"      flag 名称与短名称，名称为空时使用 mapstructure 的键，"-" 表示忽略该字段
+//	usage:"..."        帮助信息
+//	default:"..."      默认值，未设置时使用字段的当前值
+//	env:"APP_NAME"     未在命令行指定时从该环境变量读取
+//	persistent:"true"  注册为 persistent flag
+//
+// 嵌套结构体以 "parent.child" 的形式注册，与 mapstructure 的键保持一致，
+// 因此 flag 绑定与配置反序列化使用同一套键；mapstructure 的 squash 字段不增加前缀
+func FlagsFromStruct(ptr any) Flager {
+	return &structFlags{ptr: ptr}
+}
+
+type structFlags struct {
+	ptr any
+}
+
+// ApplyFlags implements Flager.
+// 标签错误属于编程错误，与 pflag 重复定义 flag 一样直接 panic
+func (sf *structFlags) ApplyFlags(ccmd *cobra.Command) {
+	v := reflect.ValueOf(sf.ptr)
+	if v.Kind() != reflect.Pointer || v.Elem().Kind() != reflect.Struct {
+		panic(fmt.Sprintf("cli: FlagsFromStruct requires a pointer to struct, got %T", sf.ptr))
+	}
+	if err := applyStructFlags(ccmd, v.Elem(), ""); err != nil {
+		panic("cli: " + err.Error())
+	}
+}
+
+func applyStructFlags(ccmd *cobra.Command, v reflect.Value, prefix string) error {
+	t := v.Type()
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		if !field.IsExported() {
+			continue
+		}
+
+		flagTag, hasFlagTag := field.Tag.Lookup("flag")
+		if flagTag == "-" {
+			continue
+		}
+		name, short, _ := strings.Cut(flagTag, ",")
+
+		key, squash := mapstructureKey(field)
+		if name != "" {
+			key = name
+		}
+		if prefix != "" {
+			key = prefix + "." + key
+		}
+
+		fv := v.Field(i)
+		reg, ok := lookupRegistrar(fv)
+		if !ok && fv.Kind() == reflect.Struct {
+			if squash {
+				key = prefix
+			}
+			if err := applyStructFlags(ccmd, fv, key); err != nil {
+				return err
+			}
+			continue
+		}
+		if !ok {
+			if hasFlagTag {
+				return fmt.Errorf("unsupported type %s for flag %q", field.Type, key)
+			}
+			continue
+		}
+
+		fs := ccmd.Flags()
+		if field.Tag.Get("persistent") == "true" {
+			fs = ccmd.PersistentFlags()
+		}
+		reg.register(fs, fv.Addr(), key, short, field.Tag.Get("usage"))
+
+		f := fs.Lookup(key)
+		if def, ok := field.Tag.Lookup("default"); ok {
+			if err := reg.setDefault(fv, f, def); err != nil {
+				return fmt.Errorf("invalid default %q for flag %q: %w", def, key, err)
+			}
+		}
+		if env := field.Tag.Get("env"); env != "" {
+			_ = fs.SetAnnotation(key, flagEnvAnnotation, []string{env})
+		}
+	}
+	return nil
+}
+
+// mapstructureKey 返回字段对应的 mapstructure 键以及是否为 squash
+func mapstructureKey(field reflect.StructField) (string, bool) {
+	tag := field.Tag.Get("mapstructure")
+	name, opts, _ := strings.Cut(tag, ",")
+	squash := strings.Contains(","+opts+",", ",squash,")
+	if name == "" {
+		name = strings.ToLower(field.Name)
+	}
+	return name, squash
+}
+
+// applyFlagEnv 对未在命令行指定且声明了环境变量的 flag，使用环境变量的值
+// 通过 FlagSet.Set 写入，使其与命令行指定的值一样高于配置文件
+func applyFlagEnv(fs *pflag.FlagSet) error {
+	var errs []error
+	fs.VisitAll(func(f *pflag.Flag) {
+		if f.Changed {
+			return
+		}
+		for _, env := range f.Annotations[flagEnvAnnotation] {
+			val, ok := os.LookupEnv(env)
+			if !ok {
+				continue
+			}
+			if err := fs.Set(f.Name, val); err != nil {
+				errs = append(errs, fmt.Errorf("invalid value %q for env %s: %w", val, env, err))
+			}
+			return
+		}
+	})
+	if len(errs) > 0 {
+		return errs[0]
+	}
+	return nil
+}
+
+type registrar struct {
+	typ reflect.Type
+	// exact 为 true 时不接受底层类型相同的自定义类型
+	exact bool
+	fn    func(fs *pflag.FlagSet, p any, name, short, usage string)
+}
+
+var pflagValueType = reflect.TypeOf((*pflag.Value)(nil)).Elem()
+
+var registrars = []registrar{
+	{typ: reflect.TypeOf(time.Duration(0)), exact: true, fn: func(fs *pflag.FlagSet, p any, name, short, usage string) {
+		fs.DurationVarP(p.(*time.Duration), name, short, *p.(*time.Duration), usage)
+	}},
+	{typ: reflect.TypeOf([]time.Duration(nil)), exact: true, fn: func(fs *pflag.FlagSet, p any, name, short, usage string) {
+		fs.DurationSliceVarP(p.(*[]time.Duration), name, short, *p.(*[]time.Duration), usage)
+	}},
+	{typ: reflect.TypeOf(""), fn: func(fs *pflag.FlagSet, p any, name, short, usage string) {
+		fs.StringVarP(p.(*string), name, short, *p.(*string), usage)
+	}},
+	{typ: reflect.TypeOf(false), fn: func(fs *pflag.FlagSet, p any, name, short, usage string) {
+		fs.BoolVarP(p.(*bool), name, short, *p.(*bool), usage)
+	}},
+	{typ: reflect.TypeOf(int(0)), fn: func(fs *pflag.FlagSet, p any, name, short, usage string) {
+		fs.IntVarP(p.(*int), name, short, *p.(*int), usage)
+	}},
+	{typ: reflect.TypeOf(int8(0)), fn: func(fs *pflag.FlagSet, p any, name, short, usage string) {
+		fs.Int8VarP(p.(*int8), name, short, *p.(*int8), usage)
+	}},
+	{typ: reflect.TypeOf(int16(0)), fn: func(fs *pflag.FlagSet, p any, name, short, usage string) {
+		fs.Int16VarP(p.(*int16), name, short, *p.(*int16), usage)
+	}},
+	{typ: reflect.TypeOf(int32(0)), fn: func(fs *pflag.FlagSet, p any, name, short, usage string) {
+		fs.Int32VarP(p.(*int32), name, short, *p.(*int32), usage)
+	}},
+	{typ: reflect.TypeOf(int64(0)), fn: func(fs *pflag.FlagSet, p any, name, short, usage string) {
+		fs.Int64VarP(p.(*int64), name, short, *p.(*int64), usage)
+	}},
+	{typ: reflect.TypeOf(uint(0)), fn: func(fs *pflag.FlagSet, p any, name, short, usage string) {
+		fs.UintVarP(p.(*uint), name, short, *p.(*uint), usage)
+	}},
+	{typ: reflect.TypeOf(uint8(0)), fn: func(fs *pflag.FlagSet, p any, name, short, usage string) {
+		fs.Uint8VarP(p.(*uint8), name, short, *p.(*uint8), usage)
+	}},
+	{typ: reflect.TypeOf(uint16(0)), fn: func(fs *pflag.FlagSet, p any, name, short, usage string) {
+		fs.Uint16VarP(p.(*uint16), name, short, *p.(*uint16), usage)
+	}},
+	{typ: reflect.TypeOf(uint32(0)), fn: func(fs *pflag.FlagSet, p any, name, short, usage string) {
+		fs.Uint32VarP(p.(*uint32), name, short, *p.(*uint32), usage)
+	}},
+	{typ: reflect.TypeOf(uint64(0)), fn: func(fs *pflag.FlagSet, p any, name, short, usage string) {
+		fs.Uint64VarP(p.(*uint64), name, short, *p.(*uint64), usage)
+	}},
+	{typ: reflect.TypeOf(float32(0)), fn: func(fs *pflag.FlagSet, p any, name, short, usage string) {
+		fs.Float32VarP(p.(*float32), name, short, *p.(*float32), usage)
+	}},
+	{typ: reflect.TypeOf(float64(0)), fn: func(fs *pflag.FlagSet, p any, name, short, usage string) {
+		fs.Float64VarP(p.(*float64), name, short, *p.(*float64), usage)
+	}},
+	{typ: reflect.TypeOf([]string(nil)), fn: func(fs *pflag.FlagSet, p any, name, short, usage string) {
+		fs.StringSliceVarP(p.(*[]string), name, short, *p.(*[]string), usage)
+	}},
+	{typ: reflect.TypeOf([]int(nil)), fn: func(fs *pflag.FlagSet, p any, name, short, usage string) {
+		fs.IntSliceVarP(p.(*[]int), name, short, *p.(*[]int), usage)
+	}},
+	{typ: reflect.TypeOf([]bool(nil)), fn: func(fs *pflag.FlagSet, p any, name, short, usage string) {
+		fs.BoolSliceVarP(p.(*[]bool), name, short, *p.(*[]bool), usage)
+	}},
+	{typ: reflect.TypeOf([]float64(nil)), fn: func(fs *pflag.FlagSet, p any, name, short, usage string) {
+		fs.Float64SliceVarP(p.(*[]float64), name, short, *p.(*[]float64), usage)
+	}},
+	{typ: reflect.TypeOf(map[string]string(nil)), fn: func(fs *pflag.FlagSet, p any, name, short, usage string) {
+		fs.StringToStringVarP(p.(*map[string]string), name, short, *p.(*map[string]string), usage)
+	}},
+	{typ: reflect.TypeOf(map[string]int(nil)), fn: func(fs *pflag.FlagSet, p any, name, short, usage string) {
+		fs.StringToIntVarP(p.(*map[string]int), name, short, *p.(*map[string]int), usage)
+	}},
+}
+
+// lookupRegistrar 查找字段对应的注册函数，实现了 pflag.Value 的字段直接使用其自身
+func lookupRegistrar(fv reflect.Value) (registrar, bool) {
+	if reflect.PointerTo(fv.Type()).Implements(pflagValueType) {
+		return registrar{typ: fv.Type(), exact: true}, true
+	}
+	for _, r := range registrars {
+		if fv.Type() == r.typ {
+			return r, true
+		}
+	}
+	for _, r := range registrars {
+		if !r.exact && reflect.PointerTo(fv.Type()).ConvertibleTo(reflect.PointerTo(r.typ)) {
+			return r, true
+		}
+	}
+	return registrar{}, false
+}
+
+func (r registrar) register(fs *pflag.FlagSet, p reflect.Value, name, short, usage string) {
+	if r.fn == nil {
+		fs.VarP(p.Interface().(pflag.Value), name, short, usage)
+		return
+	}
+	r.fn(fs, p.Convert(reflect.PointerTo(r.typ)).Interface(), name, short, usage)
+}
+
+// setDefault 解析默认值并写入字段
+// 通过临时 FlagSet 解析，避免切片与 map 类型的 flag 在命令行指定时追加到默认值之后
+func (r registrar) setDefault(fv reflect.Value, f *pflag.Flag, def string) error {
+	if r.fn == nil {
+		if err := f.Value.Set(def); err != nil {
+			return err
+		}
+		f.DefValue = f.Value.String()
+		return nil
+	}
+
+	tmp := reflect.New(fv.Type())
+	tfs := pflag.NewFlagSet("default", pflag.ContinueOnError)
+	r.fn(tfs, tmp.Convert(reflect.PointerTo(r.typ)).Interface(), "default", "", "")
+	if err := tfs.Set("default", def); err != nil {
+		return err
+	}
+
+	fv.Set(tmp.Elem())
+	f.DefValue = f.Value.String()
+	return nil
+}
@@ -0,0 +1,78 @@
+package cli
+
+import (
+	"context"
+	"reflect"
+	"testing"
+	"time"
+)
+
+type structFlagsMode string
+
+type structFlagsServer struct {
+	Host    string        `mapstructure:"host" usage:"server host" default:"127.0.0.1"`
+	Port    int           `mapstructure:"port" flag:",p" default:"8080" env:"TEST_SERVER_PORT"`
+	Timeout time.Duration `mapstructure:"timeout" default:"5s"`
+}
+
+type StructFlagsCommon struct {
+	Verbose bool `mapstructure:"verbose" flag:",v" persistent:"true"`
+}
+
+type structFlagsOptions struct {
+	StructFlagsCommon `mapstructure:",squash"`
+
+	Name    string            `mapstructure:"name"`
+	Mode    structFlagsMode   `mapstructure:"mode" default:"fast"`
+	Tags    []string          `mapstructure:"tags" default:"a,b"`
+	Labels  map[string]string `mapstructure:"labels"`
+	Server  structFlagsServer `mapstructure:"server"`
+	Ignored string            `flag:"-"`
+}
+
+func TestFlagsFromStruct(t *testing.T) {
+	t.Setenv("TEST_SERVER_PORT", "9090")
+
+	opts := &structFlagsOptions{Name: "preset"}
+	exec, err := NewCommand(&Command{
+		Use:     "app",
+		FlagSet: FlagsFromStruct(opts),
+		Config:  NewConfig(opts, ""),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	root := exec.(*executor).cobra
+	for _, name := range []string{"name", "mode", "tags", "labels", "server.host", "server.port", "server.timeout"} {
+		if root.Flags().Lookup(name) == nil {
+			t.Errorf("flag %q not registered", name)
+		}
+	}
+	if root.PersistentFlags().ShorthandLookup("v") == nil {
+		t.Error("persistent flag -v not registered")
+	}
+	if root.Flags().Lookup("ignored") != nil {
+		t.Error("flag ignored should not be registered")
+	}
+	if got := root.Flags().Lookup("server.port").DefValue; got != "8080" {
+		t.Errorf("server.port default = %q, want 8080", got)
+	}
+
+	root.SetArgs([]string{"--tags", "c", "--labels", "k=v", "-v", "--server.timeout", "1m"})
+	if err := exec.Execute(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+
+	want := structFlagsOptions{
+		StructFlagsCommon: StructFlagsCommon{Verbose: true},
+		Name:              "preset",
+		Mode:              "fast",
+		Tags:              []string{"c"},
+		Labels:            map[string]string{"k": "v"},
+		Server:            structFlagsServer{Host: "127.0.0.1", Port: 9090, Timeout: time.Minute},
+	}
+	if !reflect.DeepEqual(*opts, want) {
+		t.Errorf("got %+v, want %+v", *opts, want)
+	}
+}
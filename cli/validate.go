@@ -0,0 +1,67 @@
+package cli
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Completer 由选项结构体实现，在 flag 解析与配置加载之后、PreRun 之前补全派生的默认值
+type Completer interface {
+	Complete()
+}
+
+// Validator 由选项结构体实现，返回全部校验错误，在 Complete 之后调用
+type Validator interface {
+	Validate() []error
+}
+
+// ValidationError 聚合选项的全部校验错误
+type ValidationError struct {
+	Cmd  string
+	Errs []error
+}
+
+func (e *ValidationError) Error() string {
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "invalid options for %q:", e.Cmd)
+	for _, err := range e.Errs {
+		sb.WriteString("\n  - ")
+		sb.WriteString(err.Error())
+	}
+	return sb.String()
+}
+
+func (e *ValidationError) Unwrap() []error {
+	return e.Errs
+}
+
+// ExitCode implements ExitCoder.
+func (e *ValidationError) ExitCode() int {
+	return ExitUsage
+}
+
+// completeAndValidate 对命令的选项结构体依次调用 Complete 与 Validate
+func completeAndValidate(cmd string, opts any) error {
+	if opts == nil {
+		return nil
+	}
+	if c, ok := opts.(Completer); ok {
+		c.Complete()
+	}
+
+	v, ok := opts.(Validator)
+	if !ok {
+		return nil
+	}
+
+	var errs []error
+	for _, err := range v.Validate() {
+		if err != nil {
+			errs = append(errs, err)
+		}
+	}
+	if len(errs) > 0 {
+		return &ValidationError{Cmd: cmd, Errs: errs}
+	}
+	return nil
+}
@@ -0,0 +1,358 @@
+package cli
+
+import (
+	"fmt"
+	"io"
+	"maps"
+	"os"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	confv2 "github.com/chhz0/goose/conf/v2"
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+)
+
+// 以下 flag 值类型与 pflag 的内置类型一样绑定到调用方提供的指针，
+// 通过 pflag.FlagSet.Var 注册，例如:
+//
+//	fs.VarP(cli.NewEnumValue(&o.Output, "json", "yaml"), "output", "o", "output format")
+
+// ValueCompleter 由提供补全候选项的 flag 值实现，
+// NewCommand 会为未通过 CompleteFlags 指定补全函数的 flag 自动注册
+type ValueCompleter interface {
+	Complete(toComplete string) ([]string, cobra.ShellCompDirective)
+}
+
+// configValuer 由 String 不能被配置反序列化的 flag 值实现，
+// 绑定到配置时使用 configValue 的结果代替 String
+type configValuer interface {
+	configValue() string
+}
+
+type enumValue struct {
+	p       *string
+	def     string
+	allowed []string
+}
+
+// NewEnumValue 返回只接受 allowed 中的值的 flag 值，*p 的当前值作为默认值，
+// 帮助信息中以 a|b|c 的形式列出全部取值
+func NewEnumValue(p *string, allowed ...string) pflag.Value {
+	return &enumValue{p: p, def: *p, allowed: allowed}
+}
+
+func (v *enumValue) Set(s string) error {
+	if !slices.Contains(v.allowed, s) {
+		return fmt.Errorf("must be one of: %s", strings.Join(v.allowed, "|"))
+	}
+	*v.p = s
+	return nil
+}
+
+func (v *enumValue) String() string { return *v.p }
+func (v *enumValue) Type() string   { return strings.Join(v.allowed, "|") }
+
+// reset 恢复默认值，默认值可以不在 allowed 中，例如空字符串
+func (v *enumValue) reset() { *v.p = v.def }
+
+// Complete implements ValueCompleter.
+func (v *enumValue) Complete(toComplete string) ([]string, cobra.ShellCompDirective) {
+	return v.allowed, cobra.ShellCompDirectiveNoFileComp
+}
+
+// ParseByteSize 解析 10MiB、1.5GB、512k 形式的字节数，参见 confv2.ParseByteSize
+func ParseByteSize(s string) (int64, error) {
+	n, err := confv2.ParseByteSize(s)
+	return int64(n), err
+}
+
+// FormatByteSize 以能整除的最大 IEC 单位格式化字节数
+func FormatByteSize(n int64) string {
+	return confv2.ByteSize(n).String()
+}
+
+type byteSizeValue struct {
+	p *int64
+}
+
+// NewByteSizeValue 返回接受 10MiB 形式字节数的 flag 值，参见 ParseByteSize
+func NewByteSizeValue(p *int64) pflag.Value {
+	return &byteSizeValue{p: p}
+}
+
+func (v *byteSizeValue) Set(s string) error {
+	n, err := ParseByteSize(s)
+	if err != nil {
+		return err
+	}
+	*v.p = n
+	return nil
+}
+
+func (v *byteSizeValue) String() string      { return FormatByteSize(*v.p) }
+func (v *byteSizeValue) Type() string        { return "bytes" }
+func (v *byteSizeValue) configValue() string { return strconv.FormatInt(*v.p, 10) }
+
+// Complete implements ValueCompleter.
+func (v *byteSizeValue) Complete(toComplete string) ([]string, cobra.ShellCompDirective) {
+	return nil, cobra.ShellCompDirectiveNoFileComp
+}
+
+// PathKind 限定路径 flag 接受的文件类型
+type PathKind int
+
+const (
+	PathAny PathKind = iota
+	PathFile
+	PathDir
+)
+
+type pathValue struct {
+	p    *string
+	def  string
+	kind PathKind
+}
+
+// NewPathValue 返回只接受已存在路径的 flag 值，kind 限定为文件或目录
+func NewPathValue(p *string, kind PathKind) pflag.Value {
+	return &pathValue{p: p, def: *p, kind: kind}
+}
+
+func (v *pathValue) Set(s string) error {
+	fi, err := os.Stat(s)
+	if err != nil {
+		return err
+	}
+	switch {
+	case v.kind == PathFile && fi.IsDir():
+		return fmt.Errorf("%s is a directory", s)
+	case v.kind == PathDir && !fi.IsDir():
+		return fmt.Errorf("%s is not a directory", s)
+	}
+	*v.p = s
+	return nil
+}
+
+func (v *pathValue) String() string { return *v.p }
+
+// reset 恢复默认值，默认路径不要求存在
+func (v *pathValue) reset() { *v.p = v.def }
+
+func (v *pathValue) Type() string {
+	switch v.kind {
+	case PathFile:
+		return "file"
+	case PathDir:
+		return "dir"
+	default:
+		return "path"
+	}
+}
+
+// Complete implements ValueCompleter.
+func (v *pathValue) Complete(toComplete string) ([]string, cobra.ShellCompDirective) {
+	if v.kind == PathDir {
+		return nil, cobra.ShellCompDirectiveFilterDirs
+	}
+	return nil, cobra.ShellCompDirectiveDefault
+}
+
+const secretMask = "******"
+
+type secretValue struct {
+	p     *string
+	def   string
+	stdin func() io.Reader
+}
+
+// NewSecretValue 返回用于密码、令牌等敏感值的 flag 值，
+// "@path" 从文件读取，"-" 从标准输入读取，末尾的换行会被去除；
+// String 返回掩码，因此帮助信息与打印 flag 时不会泄露原值
+func NewSecretValue(p *string) pflag.Value {
+	return &secretValue{p: p, def: *p, stdin: func() io.Reader { return os.Stdin }}
+}
+
+func (v *secretValue) Set(s string) error {
+	var r io.Reader
+	switch {
+	case s == "-":
+		r = v.stdin()
+	case strings.HasPrefix(s, "@"):
+		f, err := os.Open(s[1:])
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+		r = f
+	default:
+		*v.p = s
+		return nil
+	}
+
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return err
+	}
+	*v.p = strings.TrimRight(string(data), "\r\n")
+	return nil
+}
+
+func (v *secretValue) String() string {
+	if *v.p == "" {
+		return ""
+	}
+	return secretMask
+}
+
+func (v *secretValue) Type() string        { return "secret" }
+func (v *secretValue) configValue() string { return *v.p }
+
+// reset 恢复默认值，DefValue 为掩码，不能用于恢复
+func (v *secretValue) reset() { *v.p = v.def }
+
+// Complete implements ValueCompleter.
+func (v *secretValue) Complete(toComplete string) ([]string, cobra.ShellCompDirective) {
+	return nil, cobra.ShellCompDirectiveDefault
+}
+
+type stringMapValue struct {
+	p       *map[string]string
+	def     map[string]string
+	changed bool
+}
+
+// NewStringMapValue 返回接受 key=value 的 flag 值，可重复指定或以逗号分隔多个键值对，
+// 第一次指定时替换默认值
+func NewStringMapValue(p *map[string]string) pflag.Value {
+	return &stringMapValue{p: p, def: maps.Clone(*p)}
+}
+
+func (v *stringMapValue) Set(s string) error {
+	m := make(map[string]string)
+	for _, pair := range strings.Split(s, ",") {
+		key, val, ok := strings.Cut(pair, "=")
+		if !ok || key == "" {
+			return fmt.Errorf("%q must be formatted as key=value", pair)
+		}
+		m[key] = val
+	}
+
+	if !v.changed || *v.p == nil {
+		*v.p = make(map[string]string, len(m))
+	}
+	for key, val := range m {
+		(*v.p)[key] = val
+	}
+	v.changed = true
+	return nil
+}
+
+func (v *stringMapValue) String() string {
+	keys := make([]string, 0, len(*v.p))
+	for key := range *v.p {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	pairs := make([]string, 0, len(keys))
+	for _, key := range keys {
+		pairs = append(pairs, key+"="+(*v.p)[key])
+	}
+	return "[" + strings.Join(pairs, ",") + "]"
+}
+
+// reset 恢复默认值，用于交互模式在两次执行之间重置 flag
+func (v *stringMapValue) reset() {
+	*v.p = maps.Clone(v.def)
+	v.changed = false
+}
+
+// Type 与 pflag 的 StringToString 一致，使绑定配置时按 map 解析
+func (v *stringMapValue) Type() string { return "stringToString" }
+
+// Complete implements ValueCompleter.
+func (v *stringMapValue) Complete(toComplete string) ([]string, cobra.ShellCompDirective) {
+	return nil, cobra.ShellCompDirectiveNoFileComp
+}
+
+type durationValue struct {
+	p        *time.Duration
+	min, max time.Duration
+}
+
+// NewDurationValue 返回限制在 [min, max] 范围内的时长 flag 值，max 为 0 表示不限制上界
+func NewDurationValue(p *time.Duration, min, max time.Duration) pflag.Value {
+	return &durationValue{p: p, min: min, max: max}
+}
+
+func (v *durationValue) Set(s string) error {
+	d, err := time.ParseDuration(s)
+	if err != nil {
+		return err
+	}
+	if d < v.min || (v.max > 0 && d > v.max) {
+		if v.max > 0 {
+			return fmt.Errorf("must be between %s and %s", v.min, v.max)
+		}
+		return fmt.Errorf("must be at least %s", v.min)
+	}
+	*v.p = d
+	return nil
+}
+
+func (v *durationValue) String() string { return v.p.String() }
+func (v *durationValue) Type() string   { return "duration" }
+
+// Complete implements ValueCompleter.
+func (v *durationValue) Complete(toComplete string) ([]string, cobra.ShellCompDirective) {
+	return nil, cobra.ShellCompDirectiveNoFileComp
+}
+
+// registerValueCompletions 为实现 ValueCompleter 且未注册补全函数的 flag 注册补全，
+// 并让读取标准输入的 secret flag 使用命令的输入流
+func registerValueCompletions(ccmd *cobra.Command) error {
+	var err error
+	visit := func(f *pflag.Flag) {
+		if s, ok := f.Value.(*secretValue); ok {
+			s.stdin = ccmd.InOrStdin
+		}
+		vc, ok := f.Value.(ValueCompleter)
+		if !ok || err != nil {
+			return
+		}
+		if _, exists := ccmd.GetFlagCompletionFunc(f.Name); exists {
+			return
+		}
+		err = ccmd.RegisterFlagCompletionFunc(f.Name, func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+			return vc.Complete(toComplete)
+		})
+	}
+	ccmd.Flags().VisitAll(visit)
+	ccmd.PersistentFlags().VisitAll(visit)
+	return err
+}
+
+// configFlags 返回用于绑定配置的 FlagSet，实现 configValuer 的 flag 以可反序列化的形式提供值
+func configFlags(fs *pflag.FlagSet) *pflag.FlagSet {
+	out := pflag.NewFlagSet(fs.Name(), pflag.ContinueOnError)
+	fs.VisitAll(func(f *pflag.Flag) {
+		if cv, ok := f.Value.(configValuer); ok {
+			cf := *f
+			cf.Value = configFlagValue{Value: f.Value, cv: cv}
+			f = &cf
+		}
+		out.AddFlag(f)
+	})
+	return out
+}
+
+type configFlagValue struct {
+	pflag.Value
+	cv configValuer
+}
+
+func (v configFlagValue) String() string { return v.cv.configValue() }
@@ -0,0 +1,197 @@
+package cli
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+)
+
+func TestParseByteSize(t *testing.T) {
+	tests := []struct {
+		in      string
+		want    int64
+		wantErr bool
+	}{
+		{in: "1024", want: 1024},
+		{in: "10MiB", want: 10 << 20},
+		{in: "10mb", want: 10_000_000},
+		{in: "512k", want: 512 << 10},
+		{in: "1.5GiB", want: 3 << 29},
+		{in: "2 TB", want: 2e12},
+		{in: "10XB", wantErr: true},
+		{in: "MiB", wantErr: true},
+	}
+	for _, tt := range tests {
+		got, err := ParseByteSize(tt.in)
+		if (err != nil) != tt.wantErr || got != tt.want {
+			t.Errorf("ParseByteSize(%q) = %d, %v, want %d", tt.in, got, err, tt.want)
+		}
+	}
+
+	for n, want := range map[int64]string{0: "0B", 1500: "1500B", 2048: "2KiB", 10 << 20: "10MiB"} {
+		if got := FormatByteSize(n); got != want {
+			t.Errorf("FormatByteSize(%d) = %q, want %q", n, got, want)
+		}
+	}
+}
+
+func TestFlagValues(t *testing.T) {
+	dir := t.TempDir()
+	file := filepath.Join(dir, "token")
+	if err := os.WriteFile(file, []byte("s3cret\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	var (
+		format  = "json"
+		size    int64
+		path    string
+		secret  string
+		labels  = map[string]string{"env": "dev"}
+		timeout = time.Second
+	)
+	fs := pflag.NewFlagSet("test", pflag.ContinueOnError)
+	fs.Var(NewEnumValue(&format, "json", "yaml"), "format", "")
+	fs.Var(NewByteSizeValue(&size), "size", "")
+	fs.Var(NewPathValue(&path, PathDir), "dir", "")
+	fs.Var(NewSecretValue(&secret), "token", "")
+	fs.Var(NewStringMapValue(&labels), "label", "")
+	fs.Var(NewDurationValue(&timeout, time.Second, time.Minute), "timeout", "")
+
+	tests := []struct {
+		args    []string
+		wantErr string
+	}{
+		{args: []string{"--format", "xml"}, wantErr: "must be one of: json|yaml"},
+		{args: []string{"--size", "ten"}, wantErr: "invalid byte size"},
+		{args: []string{"--dir", file}, wantErr: "is not a directory"},
+		{args: []string{"--label", "novalue"}, wantErr: "key=value"},
+		{args: []string{"--timeout", "1h"}, wantErr: "must be between 1s and 1m0s"},
+		{args: []string{
+			"--format", "yaml", "--size", "10MiB", "--dir", dir, "--token", "@" + file,
+			"--label", "a=1,b=2", "--label", "c=3", "--timeout", "30s",
+		}},
+	}
+	for _, tt := range tests {
+		err := fs.Parse(tt.args)
+		if tt.wantErr != "" {
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("Parse(%q) error = %v, want %q", tt.args, err, tt.wantErr)
+			}
+			continue
+		}
+		if err != nil {
+			t.Fatalf("Parse(%q) error = %v", tt.args, err)
+		}
+	}
+
+	if format != "yaml" || size != 10<<20 || path != dir || secret != "s3cret" || timeout != 30*time.Second {
+		t.Errorf("values = %q %d %q %q %s", format, size, path, secret, timeout)
+	}
+	if want := map[string]string{"a": "1", "b": "2", "c": "3"}; !reflect.DeepEqual(labels, want) {
+		t.Errorf("labels = %v, want %v", labels, want)
+	}
+	if got := fs.Lookup("token").Value.String(); got != secretMask {
+		t.Errorf("secret String() = %q, want mask", got)
+	}
+	if got := fs.Lookup("format").Value.Type(); got != "json|yaml" {
+		t.Errorf("enum Type() = %q", got)
+	}
+}
+
+func TestFlagValues_Command(t *testing.T) {
+	type options struct {
+		Format string `mapstructure:"format"`
+		Size   int64  `mapstructure:"size"`
+		Token  string `mapstructure:"token"`
+	}
+	opts := &options{Format: "json"}
+	var got options
+	var stdout bytes.Buffer
+	newExec := func(args ...string) Exec {
+		exec, err := NewCommand(&Command{
+			Use: "app",
+			FlagSet: &FlagSet{
+				Local: func(pfs *pflag.FlagSet) {
+					pfs.Var(NewEnumValue(&opts.Format, "json", "yaml"), "format", "output format")
+					pfs.Var(NewByteSizeValue(&opts.Size), "size", "")
+					pfs.Var(NewSecretValue(&opts.Token), "token", "")
+				},
+			},
+			Config: NewConfig(opts, "app", t.TempDir()),
+			Run: func(ctx context.Context, args []string) error {
+				got = *opts
+				return nil
+			},
+		}, WithArgs(args...), WithIOStreams(IOStreams{In: strings.NewReader("from-stdin\n"), Out: &stdout}))
+		if err != nil {
+			t.Fatal(err)
+		}
+		return exec
+	}
+
+	if err := newExec("--format", "yaml", "--size", "1KiB", "--token", "-").Execute(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if want := (options{Format: "yaml", Size: 1024, Token: "from-stdin"}); got != want {
+		t.Errorf("options = %+v, want %+v", got, want)
+	}
+
+	stdout.Reset()
+	if err := newExec("__completeNoDesc", "--format", "").Execute(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if want := fmt.Sprintf("json\nyaml\n:%d\n", cobra.ShellCompDirectiveNoFileComp); stdout.String() != want {
+		t.Errorf("completion = %q, want %q", stdout.String(), want)
+	}
+}
+
+func TestFlagValues_Shell(t *testing.T) {
+	dir := t.TempDir()
+	var format, path string
+	token := "default-token"
+	root := &Command{
+		Use: "app",
+		Commands: []Commander{
+			&Command{
+				Use: "show",
+				FlagSet: &FlagSet{Local: func(pfs *pflag.FlagSet) {
+					pfs.Var(NewEnumValue(&format, "json", "yaml"), "format", "output format")
+					pfs.Var(NewPathValue(&path, PathDir), "dir", "")
+					pfs.Var(NewSecretValue(&token), "token", "")
+				}},
+				Run: func(ctx context.Context, args []string) error {
+					fmt.Fprintf(IO(ctx).Out, "%q %q %q\n", format, path, token)
+					return nil
+				},
+			},
+		},
+	}
+
+	input := fmt.Sprintf("show --format yaml --dir %s --token abc\nshow\n", dir)
+	var stdout, stderr bytes.Buffer
+	exec, err := Shell(root, WithIOStreams(IOStreams{In: strings.NewReader(input), Out: &stdout, ErrOut: &stderr}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := exec.Execute(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+
+	want := fmt.Sprintf("\"yaml\" %q \"abc\"\n\"\" \"\" \"default-token\"\n", dir)
+	if stdout.String() != want {
+		t.Errorf("stdout = %q, want %q", stdout.String(), want)
+	}
+	if stderr.Len() != 0 {
+		t.Errorf("stderr = %q", stderr.String())
+	}
+}
@@ -1,2 +1,145 @@
 package cli
 
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"runtime"
+	"runtime/debug"
+
+	"github.com/spf13/pflag"
+	"gopkg.in/yaml.v2"
+)
+
+// 构建信息，通过 -ldflags 注入，例如:
+//
+//	-X github.com/chhz0/goose/cli.gitVersion=v1.0.0
+//	-X github.com/chhz0/goose/cli.gitCommit=$(git rev-parse HEAD)
+//	-X github.com/chhz0/goose/cli.gitTreeState=clean
+//	-X github.com/chhz0/goose/cli.buildDate=$(date -u +'%Y-%m-%dT%H:%M:%SZ')
+//
+// 未注入时回退到 debug.ReadBuildInfo 中记录的模块版本与 vcs 信息
+var (
+	gitVersion   = ""
+	gitCommit    = ""
+	gitTreeState = ""
+	buildDate    = ""
+)
+
+// VersionInfo 描述一个二进制的构建信息
+type VersionInfo struct {
+	GitVersion   string `json:"gitVersion" yaml:"gitVersion"`
+	GitCommit    string `json:"gitCommit" yaml:"gitCommit"`
+	GitTreeState string `json:"gitTreeState" yaml:"gitTreeState"`
+	BuildDate    string `json:"buildDate" yaml:"buildDate"`
+	GoVersion    string `json:"goVersion" yaml:"goVersion"`
+	Compiler     string `json:"compiler" yaml:"compiler"`
+	Platform     string `json:"platform" yaml:"platform"`
+}
+
+// develVersion 非模块方式构建(如 go build ./...)时 debug.ReadBuildInfo 报告的版本
+const develVersion = "(devel)"
+
+// Version 返回当前二进制的构建信息
+func Version() VersionInfo {
+	bi, _ := debug.ReadBuildInfo()
+	return versionInfo(bi)
+}
+
+// versionInfo 合并 ldflags 注入的信息与 bi, bi 为 nil 时仅使用注入的信息
+func versionInfo(bi *debug.BuildInfo) VersionInfo {
+	info := VersionInfo{
+		GitVersion:   gitVersion,
+		GitCommit:    gitCommit,
+		GitTreeState: gitTreeState,
+		BuildDate:    buildDate,
+		GoVersion:    runtime.Version(),
+		Compiler:     runtime.Compiler,
+		Platform:     fmt.Sprintf("%s/%s", runtime.GOOS, runtime.GOARCH),
+	}
+
+	if bi != nil {
+		if info.GitVersion == "" && bi.Main.Version != "" && bi.Main.Version != develVersion {
+			info.GitVersion = bi.Main.Version
+		}
+		for _, s := range bi.Settings {
+			switch s.Key {
+			case "vcs.revision":
+				if info.GitCommit == "" {
+					info.GitCommit = s.Value
+				}
+			case "vcs.modified":
+				if info.GitTreeState == "" {
+					info.GitTreeState = "clean"
+					if s.Value == "true" {
+						info.GitTreeState = "dirty"
+					}
+				}
+			case "vcs.time":
+				if info.BuildDate == "" {
+					info.BuildDate = s.Value
+				}
+			}
+		}
+	}
+
+	if info.GitVersion == "" {
+		info.GitVersion = "v0.0.0-unknown"
+	}
+
+	return info
+}
+
+// String 返回人类可读的构建信息
+func (v VersionInfo) String() string {
+	return fmt.Sprintf(
+		"Version:    %s\nGitCommit:  %s\nTreeState:  %s\nBuildDate:  %s\nGoVersion:  %s\nCompiler:   %s\nPlatform:   %s\n",
+		v.GitVersion, v.GitCommit, v.GitTreeState, v.BuildDate, v.GoVersion, v.Compiler, v.Platform,
+	)
+}
+
+// VersionCommand 返回一个 version 子命令，支持 --output json|yaml|short
+func VersionCommand() Commander {
+	var output string
+
+	cmd := &Command{
+		Use:   "version",
+		Short: "Print the version information.",
+		Long:  "Print the version, git commit, tree state, build date, Go version and platform of this binary.",
+		FlagSet: &FlagSet{
+			Local: func(pfs *pflag.FlagSet) {
+				pfs.VarP(NewEnumValue(&output, "json", "yaml", "short"), "output", "o", "output format")
+			},
+		},
+	}
+	cmd.Run = func(ctx context.Context, args []string) error {
+		out := cmd.Cobra().OutOrStdout()
+		info := Version()
+
+		switch output {
+		case "":
+			_, err := fmt.Fprint(out, info.String())
+			return err
+		case "short":
+			_, err := fmt.Fprintln(out, info.GitVersion)
+			return err
+		case "json":
+			buf, err := json.MarshalIndent(info, "", "  ")
+			if err != nil {
+				return err
+			}
+			_, err = fmt.Fprintln(out, string(buf))
+			return err
+		case "yaml":
+			buf, err := yaml.Marshal(info)
+			if err != nil {
+				return err
+			}
+			_, err = out.Write(buf)
+			return err
+		}
+		return nil
+	}
+
+	return cmd
+}
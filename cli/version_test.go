@@ -0,0 +1,103 @@
+package cli
+
+import (
+	"bytes"
+	"encoding/json"
+	"runtime/debug"
+	"strings"
+	"testing"
+
+	"gopkg.in/yaml.v2"
+)
+
+func TestVersionInfo(t *testing.T) {
+	tests := []struct {
+		name    string
+		ldflags string
+		bi      *debug.BuildInfo
+		want    string
+	}{
+		{name: "no build info", want: "v0.0.0-unknown"},
+		{name: "devel", bi: &debug.BuildInfo{Main: debug.Module{Version: "(devel)"}}, want: "v0.0.0-unknown"},
+		{name: "module version", bi: &debug.BuildInfo{Main: debug.Module{Version: "v1.2.3"}}, want: "v1.2.3"},
+		{name: "ldflags override", ldflags: "v2.0.0", bi: &debug.BuildInfo{Main: debug.Module{Version: "v1.2.3"}}, want: "v2.0.0"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			defer func(v string) { gitVersion = v }(gitVersion)
+			gitVersion = tt.ldflags
+
+			if got := versionInfo(tt.bi).GitVersion; got != tt.want {
+				t.Errorf("GitVersion = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestVersionInfo_VCS(t *testing.T) {
+	info := versionInfo(&debug.BuildInfo{Settings: []debug.BuildSetting{
+		{Key: "vcs.revision", Value: "abc123"},
+		{Key: "vcs.modified", Value: "true"},
+		{Key: "vcs.time", Value: "2025-01-01T00:00:00Z"},
+	}})
+	if info.GitCommit != "abc123" || info.GitTreeState != "dirty" || info.BuildDate != "2025-01-01T00:00:00Z" {
+		t.Errorf("unexpected vcs info: %+v", info)
+	}
+}
+
+func TestVersionCommand(t *testing.T) {
+	defer func(v string) { gitVersion = v }(gitVersion)
+	gitVersion = "v1.0.0"
+
+	tests := []struct {
+		name  string
+		args  []string
+		check func(t *testing.T, out string)
+	}{
+		{name: "default", args: []string{"version"}, check: func(t *testing.T, out string) {
+			if !strings.Contains(out, "Version:    v1.0.0\n") {
+				t.Errorf("output = %q", out)
+			}
+		}},
+		{name: "short", args: []string{"version", "-o", "short"}, check: func(t *testing.T, out string) {
+			if out != "v1.0.0\n" {
+				t.Errorf("output = %q", out)
+			}
+		}},
+		{name: "json", args: []string{"version", "-o", "json"}, check: func(t *testing.T, out string) {
+			var info VersionInfo
+			if err := json.Unmarshal([]byte(out), &info); err != nil || info.GitVersion != "v1.0.0" {
+				t.Errorf("output = %q, err = %v", out, err)
+			}
+		}},
+		{name: "yaml", args: []string{"version", "--output", "yaml"}, check: func(t *testing.T, out string) {
+			var info VersionInfo
+			if err := yaml.Unmarshal([]byte(out), &info); err != nil || info.GitVersion != "v1.0.0" {
+				t.Errorf("output = %q, err = %v", out, err)
+			}
+		}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var out bytes.Buffer
+			root := &Command{Use: "app", Commands: []Commander{VersionCommand()}}
+			exec, err := NewCommand(root, WithArgs(tt.args...), WithIOStreams(IOStreams{Out: &out, ErrOut: &out}))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if err := exec.Execute(t.Context()); err != nil {
+				t.Fatal(err)
+			}
+			tt.check(t, out.String())
+		})
+	}
+
+	exec, err := NewCommand(&Command{Use: "app", Commands: []Commander{VersionCommand()}},
+		WithArgs("version", "-o", "xml"), WithIOStreams(IOStreams{Out: &bytes.Buffer{}, ErrOut: &bytes.Buffer{}}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if code := exec.Run(t.Context()); code != ExitUsage {
+		t.Errorf("Run() with invalid output format = %d, want %d", code, ExitUsage)
+	}
+}
@@ -0,0 +1,77 @@
+package confv2
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// ByteSize 字节数, 配置中可以写作整数或 10MiB、1.5GB、512k 形式的字符串
+type ByteSize int64
+
+var byteUnits = map[string]float64{
+	"":  1,
+	"b": 1,
+	"k": 1 << 10, "kb": 1e3, "kib": 1 << 10,
+	"m": 1 << 20, "mb": 1e6, "mib": 1 << 20,
+	"g": 1 << 30, "gb": 1e9, "gib": 1 << 30,
+	"t": 1 << 40, "tb": 1e12, "tib": 1 << 40,
+	"p": 1 << 50, "pb": 1e15, "pib": 1 << 50,
+}
+
+// ParseByteSize 解析 10MiB、1.5GB、512k 形式的字节数, 单位不区分大小写,
+// KiB/MiB 等与单字母单位以 1024 为基数, KB/MB 等以 1000 为基数
+func ParseByteSize(s string) (ByteSize, error) {
+	s = strings.TrimSpace(s)
+	i := strings.IndexFunc(s, func(r rune) bool {
+		return !unicode.IsDigit(r) && r != '.'
+	})
+	if i < 0 {
+		i = len(s)
+	}
+
+	num, err := strconv.ParseFloat(s[:i], 64)
+	if err != nil {
+		return 0, fmt.Errorf("invalid byte size %q", s)
+	}
+	unit, ok := byteUnits[strings.ToLower(strings.TrimSpace(s[i:]))]
+	if !ok {
+		return 0, fmt.Errorf("invalid byte size unit in %q", s)
+	}
+	size := num * unit
+	if size > math.MaxInt64 {
+		return 0, fmt.Errorf("byte size %q overflows int64", s)
+	}
+	return ByteSize(size), nil
+}
+
+// String 以能整除的最大 IEC 单位格式化字节数
+func (b ByteSize) String() string {
+	n := int64(b)
+	for _, u := range []struct {
+		name string
+		size int64
+	}{{"PiB", 1 << 50}, {"TiB", 1 << 40}, {"GiB", 1 << 30}, {"MiB", 1 << 20}, {"KiB", 1 << 10}} {
+		if n != 0 && n%u.size == 0 {
+			return strconv.FormatInt(n/u.size, 10) + u.name
+		}
+	}
+	return strconv.FormatInt(n, 10) + "B"
+}
+
+// UnmarshalText implements encoding.TextUnmarshaler.
+func (b *ByteSize) UnmarshalText(text []byte) error {
+	n, err := ParseByteSize(string(text))
+	if err != nil {
+		return err
+	}
+	*b = n
+	return nil
+}
+
+// MarshalText implements encoding.TextMarshaler.
+func (b ByteSize) MarshalText() ([]byte, error) {
+	return []byte(b.String()), nil
+}
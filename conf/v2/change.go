@@ -0,0 +1,145 @@
+package confv2
+
+import (
+	"path"
+	"reflect"
+	"slices"
+	"strings"
+)
+
+// KeyChange 一个配置项在重新加载前后的值, 新增的键 Old 为 nil, 删除的键 New 为 nil
+type KeyChange struct {
+	Key string
+	Old any
+	New any
+}
+
+// ChangeEvent 一次重新加载中与订阅模式匹配的全部变化, 按键排序
+type ChangeEvent struct {
+	Changes []KeyChange
+}
+
+// Keys 返回发生变化的键
+func (e ChangeEvent) Keys() []string {
+	keys := make([]string, 0, len(e.Changes))
+	for _, ch := range e.Changes {
+		keys = append(keys, ch.Key)
+	}
+	return keys
+}
+
+type subscription struct {
+	id      int
+	pattern []string
+	fn      func(ChangeEvent)
+}
+
+// OnChange 订阅与 pattern 匹配的配置项的变化, 返回取消订阅的函数
+// pattern 以 "." 分隔, 每一段支持 path.Match 的通配符, "**" 匹配任意多段,
+// 例如 server.port、server.*、log.**、**
+//
+// 回调在重新加载完成且释放配置锁之后按重新加载的顺序依次调用, 可以在回调中读取配置,
+// 但不能在回调中同步等待下一次重新加载
+func (c *Config) OnChange(pattern string, fn func(ev ChangeEvent)) func() {
+	c.subMu.Lock()
+	defer c.subMu.Unlock()
+
+	c.subSeq++
+	id := c.subSeq
+	c.subs = append(c.subs, subscription{
+		id:      id,
+		pattern: strings.Split(strings.ToLower(pattern), "."),
+		fn:      fn,
+	})
+	return func() {
+		c.subMu.Lock()
+		defer c.subMu.Unlock()
+		c.subs = slices.DeleteFunc(c.subs, func(s subscription) bool { return s.id == id })
+	}
+}
+
+// notify 将 old 与 cur 的差异分发给订阅者, 调用方需持有 reloadMu 以保证顺序
+func (c *Config) notify(old, cur map[string]any) {
+	c.subMu.Lock()
+	subs := slices.Clone(c.subs)
+	c.subMu.Unlock()
+	if len(subs) == 0 {
+		return
+	}
+
+	changes := diffSettings(old, cur)
+	if len(changes) == 0 {
+		return
+	}
+	for _, sub := range subs {
+		var ev ChangeEvent
+		for _, ch := range changes {
+			if matchKey(sub.pattern, strings.Split(ch.Key, ".")) {
+				ev.Changes = append(ev.Changes, ch)
+			}
+		}
+		if len(ev.Changes) > 0 {
+			sub.fn(ev)
+		}
+	}
+}
+
+// diffSettings 比较两份 AllSettings 展开后的叶子节点, 列表整体作为一个值比较
+func diffSettings(old, cur map[string]any) []KeyChange {
+	before, after := flatten(old, "", nil), flatten(cur, "", nil)
+
+	var changes []KeyChange
+	for k, ov := range before {
+		nv, ok := after[k]
+		if !ok || !reflect.DeepEqual(ov, nv) {
+			changes = append(changes, KeyChange{Key: k, Old: ov, New: nv})
+		}
+	}
+	for k, nv := range after {
+		if _, ok := before[k]; !ok {
+			changes = append(changes, KeyChange{Key: k, New: nv})
+		}
+	}
+	slices.SortFunc(changes, func(a, b KeyChange) int { return strings.Compare(a.Key, b.Key) })
+	return changes
+}
+
+func flatten(m map[string]any, prefix string, out map[string]any) map[string]any {
+	if out == nil {
+		out = make(map[string]any)
+	}
+	for k, v := range m {
+		key := k
+		if prefix != "" {
+			key = prefix + "." + k
+		}
+		if sub, ok := v.(map[string]any); ok && len(sub) > 0 {
+			flatten(sub, key, out)
+			continue
+		}
+		out[key] = v
+	}
+	return out
+}
+
+// matchKey 按段匹配键, "**" 匹配零个或多个段
+func matchKey(pattern, key []string) bool {
+	if len(pattern) == 0 {
+		return len(key) == 0
+	}
+	if pattern[0] == "**" {
+		for i := 0; i <= len(key); i++ {
+			if matchKey(pattern[1:], key[i:]) {
+				return true
+			}
+		}
+		return false
+	}
+	if len(key) == 0 {
+		return false
+	}
+	if ok, _ := path.Match(pattern[0], key[0]); !ok {
+		return false
+	}
+	return matchKey(pattern[1:], key[1:])
+}
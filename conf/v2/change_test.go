@@ -0,0 +1,94 @@
+package confv2
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestMatchKey(t *testing.T) {
+	tests := []struct {
+		pattern string
+		key     string
+		want    bool
+	}{
+		{"server.port", "server.port", true},
+		{"server.*", "server.port", true},
+		{"server.*", "server.tls.cert", false},
+		{"server.**", "server.tls.cert", true},
+		{"**.cert", "server.tls.cert", true},
+		{"**", "app", true},
+		{"log.*", "server.port", false},
+	}
+	for _, tt := range tests {
+		got := matchKey(strings.Split(tt.pattern, "."), strings.Split(tt.key, "."))
+		if got != tt.want {
+			t.Errorf("matchKey(%q, %q) = %v, want %v", tt.pattern, tt.key, got, tt.want)
+		}
+	}
+}
+
+func TestDiffSettings(t *testing.T) {
+	old := map[string]any{
+		"app":    "a",
+		"tags":   []any{"x"},
+		"server": map[string]any{"host": "localhost", "port": 80},
+	}
+	cur := map[string]any{
+		"app":    "a",
+		"tags":   []any{"x", "y"},
+		"server": map[string]any{"port": 8080},
+		"log":    map[string]any{"level": "info"},
+	}
+
+	want := []KeyChange{
+		{Key: "log.level", New: "info"},
+		{Key: "server.host", Old: "localhost"},
+		{Key: "server.port", Old: 80, New: 8080},
+		{Key: "tags", Old: []any{"x"}, New: []any{"x", "y"}},
+	}
+	if got := diffSettings(old, cur); !reflect.DeepEqual(got, want) {
+		t.Errorf("diffSettings() = %+v, want %+v", got, want)
+	}
+}
+
+func TestOnChange(t *testing.T) {
+	dir := t.TempDir()
+	file := filepath.Join(dir, "config.yaml")
+	writeFile(t, file, "app: a\nserver:\n  port: 80\n")
+
+	c, err := Init().WithConfigFilePath(file, "yaml").WithWatch(true).Loading()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	events := make(chan ChangeEvent, 4)
+	c.OnChange("server.*", func(ev ChangeEvent) {
+		// 回调中可以读取配置
+		_ = c.Get("server.port")
+		events <- ev
+	})
+	cancel := c.OnChange("app", func(ev ChangeEvent) {
+		t.Errorf("unexpected event after cancel: %+v", ev)
+	})
+	cancel()
+
+	// 以重命名的方式原子替换, 避免读到写入一半的文件
+	tmp := filepath.Join(t.TempDir(), "config.yaml")
+	writeFile(t, tmp, "app: b\nserver:\n  port: 8080\n")
+	if err := os.Rename(tmp, file); err != nil {
+		t.Fatal(err)
+	}
+	select {
+	case ev := <-events:
+		want := []KeyChange{{Key: "server.port", Old: 80, New: 8080}}
+		if !reflect.DeepEqual(ev.Changes, want) {
+			t.Errorf("changes = %+v, want %+v", ev.Changes, want)
+		}
+	case <-time.After(3 * time.Second):
+		t.Fatal("no change event")
+	}
+}
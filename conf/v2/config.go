@@ -1,9 +1,15 @@
 package confv2
 
 import (
+	"bytes"
 	"context"
+	"errors"
+	"fmt"
+	"io/fs"
+	"path/filepath"
+	"reflect"
+	"strings"
 	"sync"
-	"time"
 
 	"github.com/fsnotify/fsnotify"
 	"github.com/spf13/viper"
@@ -13,46 +19,147 @@ type Config struct {
 	v    *viper.Viper
 	opts Options
 	mu   sync.RWMutex
+
+	// files 最近一次加载使用的配置文件
+	files []string
+
+	// reloadMu 串行化重新加载与变化通知, 保证订阅者按顺序收到变化
+	reloadMu sync.Mutex
+	subMu    sync.Mutex
+	subs     []subscription
+	subSeq   int
+
+	snapMu    sync.Mutex
+	snapshots []snapshotter
+
+	remote *remoteState
+	// ctx 在 Close 时结束, 用于停止监听
+	ctx    context.Context
+	cancel context.CancelFunc
+}
+
+// load 按优先级从低到高加载全部配置来源并返回新的 viper 实例:
+//
+//	default < config file < config layers < remote < dotenv < env < flags < args < set
+//
+// 首次加载与配置文件变化后的重新加载共用该流程
+func (c *Config) load() (*viper.Viper, []string, error) {
+	v := viper.New()
+	c.setDefault(v)
+
+	settings, files, err := c.readConfigFiles()
+	if err != nil {
+		return nil, nil, err
+	}
+	if c.remote != nil {
+		remote, err := c.remote.settings()
+		if err != nil {
+			return nil, nil, err
+		}
+		settings = mergeSettings(settings, remote, c.opts.listMerge)
+	}
+	dotEnv, err := c.readDotEnv()
+	if err != nil {
+		return nil, nil, err
+	}
+	settings = mergeSettings(settings, dotEnv, ListReplace)
+	if err := v.MergeConfigMap(settings); err != nil {
+		return nil, nil, err
+	}
+
+	c.setupEnv(v)
+	c.bindPFlags(v)
+	c.setArgs(v)
+	c.set(v)
+	return v, files, nil
+}
+
+func (c *Config) setDefault(v *viper.Viper) {
+	defaults := make(map[string]string)
+	if c.opts.unmarshalTo != nil {
+		tagDefaults(reflect.TypeOf(c.opts.unmarshalTo), "", defaults)
+	}
+	for _, s := range c.snapshotList() {
+		tagDefaults(s.targetType(), "", defaults)
+	}
+	for k, val := range defaults {
+		v.SetDefault(k, val)
+	}
+	for k, val := range c.opts.defaults {
+		v.SetDefault(k, val)
+	}
 }
 
-func (c *Config) setDefault() {
-	for k, v := range c.opts.defaults {
-		c.v.SetDefault(k, v)
+// readConfigFiles 读取配置文件与各层配置并按顺序深度合并
+func (c *Config) readConfigFiles() (map[string]any, []string, error) {
+	settings, files, err := c.readConfigFile()
+	if err != nil {
+		return nil, nil, err
 	}
+
+	for _, layer := range c.opts.layers {
+		matched, err := layer.resolve()
+		if err != nil {
+			return nil, nil, err
+		}
+		for _, file := range matched {
+			s, err := readFile(file, layer.Type)
+			if err != nil {
+				return nil, nil, c.opts.errReadHandler(fmt.Errorf("%w: %s: %v", ErrConfigRead, file, err))
+			}
+			settings = mergeSettings(settings, s, c.opts.listMerge)
+			files = append(files, file)
+		}
+	}
+	return settings, files, nil
 }
 
-func (c *Config) loadConfigFile() error {
+func (c *Config) readConfigFile() (map[string]any, []string, error) {
 	if c.opts.configFile == nil {
-		return nil
+		return nil, nil, nil
 	}
 
-	if c.opts.configFile.io != nil {
-		c.v.SetConfigType(c.opts.configFile.typ)
-		if err := c.v.ReadConfig(c.opts.configFile.io); err != nil {
-			return c.opts.errReadHandler(ErrReaderIO)
+	v := viper.New()
+	if c.opts.configFile.data != nil {
+		v.SetConfigType(c.opts.configFile.typ)
+		if err := v.ReadConfig(bytes.NewReader(c.opts.configFile.data)); err != nil {
+			return nil, nil, c.opts.errReadHandler(ErrReaderIO)
 		}
-		return nil
+		return v.AllSettings(), nil, nil
 	}
 
-	c.v.SetConfigName(c.opts.configFile.name)
-	c.v.SetConfigType(c.opts.configFile.typ)
-	for _, path := range c.opts.configFile.paths {
-		c.v.AddConfigPath(path)
+	if c.opts.configFile.file != "" {
+		v.SetConfigFile(c.opts.configFile.file)
+		if c.opts.configFile.typ != "" {
+			v.SetConfigType(c.opts.configFile.typ)
+		}
+	} else {
+		v.SetConfigName(c.opts.configFile.name)
+		v.SetConfigType(c.opts.configFile.typ)
+		for _, path := range c.opts.configFile.paths {
+			v.AddConfigPath(path)
+		}
 	}
 
-	if err := c.v.ReadInConfig(); err != nil {
+	if err := v.ReadInConfig(); err != nil {
 		if _, ok := err.(viper.ConfigFileNotFoundError); ok {
-			return ErrConfigNotFound
+			if c.opts.configOptional {
+				return nil, nil, nil
+			}
+			return nil, nil, ErrConfigNotFound
 		}
-		return c.opts.errReadHandler(ErrConfigRead)
+		if errors.Is(err, fs.ErrNotExist) {
+			return nil, nil, ErrConfigNotFound
+		}
+		return nil, nil, c.opts.errReadHandler(ErrConfigRead)
 	}
 
-	return nil
+	return v.AllSettings(), []string{v.ConfigFileUsed()}, nil
 }
 
-func (c *Config) loadDotEnv() error {
+func (c *Config) readDotEnv() (map[string]any, error) {
 	if c.opts.dotEnv == nil {
-		return nil
+		return nil, nil
 	}
 
 	v := viper.New()
@@ -64,82 +171,192 @@ func (c *Config) loadDotEnv() error {
 
 	if err := v.ReadInConfig(); err != nil {
 		if _, ok := err.(viper.ConfigFileNotFoundError); ok {
-			return ErrDotEnvNotFound
+			return nil, ErrDotEnvNotFound
 		}
-		return c.opts.errReadHandler(ErrDotEnvRead)
+		return nil, c.opts.errReadHandler(ErrDotEnvRead)
 	}
 
-	return c.v.MergeConfigMap(v.AllSettings())
+	return v.AllSettings(), nil
 }
 
-func (c *Config) setupEnv() {
+func (c *Config) setupEnv(v *viper.Viper) {
 	if c.opts.envPrefix != "" {
-		c.v.SetEnvPrefix(c.opts.envPrefix)
+		v.SetEnvPrefix(c.opts.envPrefix)
 	}
 	if c.opts.envReplacer != nil {
-		c.v.SetEnvKeyReplacer(c.opts.envReplacer)
+		v.SetEnvKeyReplacer(c.opts.envReplacer)
 	}
-	c.v.AutomaticEnv()
-	_ = c.v.BindEnv(c.opts.envBinds...)
+	v.AutomaticEnv()
+	_ = v.BindEnv(c.opts.envBinds...)
 }
 
-func (c *Config) bindPFlags() {
+func (c *Config) bindPFlags(v *viper.Viper) {
 	for _, fs := range c.opts.flags {
-		_ = c.v.BindPFlags(fs)
+		_ = v.BindPFlags(fs)
 	}
 }
 
-func (c *Config) setArgs() {
-	for k, v := range c.opts.args {
-		c.v.Set(k, v)
+func (c *Config) setArgs(v *viper.Viper) {
+	for k, val := range c.opts.args {
+		v.Set(k, val)
 	}
 }
 
-func (c *Config) set() {
-	for k, v := range c.opts.sets {
-		c.v.Set(k, v)
+func (c *Config) set(v *viper.Viper) {
+	for k, val := range c.opts.sets {
+		v.Set(k, val)
 	}
 }
 
-func (c *Config) watchConfig() {
-	c.v.OnConfigChange(func(in fsnotify.Event) {
-		c.mu.Lock()
-		defer c.mu.Unlock()
+// reload 重新执行加载流程, 反序列化与校验均通过后才替换当前配置
+func (c *Config) reload() error {
+	c.reloadMu.Lock()
+	defer c.reloadMu.Unlock()
 
-		if err := c.v.ReadInConfig(); err != nil {
-			_ = c.opts.errReadHandler(err)
-			return
+	v, files, err := c.load()
+	if err != nil {
+		return c.opts.errReadHandler(err)
+	}
+	target, err := c.decodeTarget(v)
+	if err != nil {
+		return c.opts.errReadHandler(err)
+	}
+	// 所有快照都构建并校验通过后才一起替换, 任何一个失败都保留上一次的配置
+	snapshots := c.snapshotList()
+	stores := make([]func(), 0, len(snapshots))
+	for _, s := range snapshots {
+		store, err := s.build(v)
+		if err != nil {
+			return c.opts.errReadHandler(err)
 		}
+		stores = append(stores, store)
+	}
 
-		if c.opts.unmarshalTo != nil {
-			if err := c.v.Unmarshal(c.opts.unmarshalTo); err != nil {
+	cur := v.AllSettings()
+	c.mu.Lock()
+	old := c.v.AllSettings()
+	c.v, c.files = v, files
+	c.applyTarget(target)
+	for _, store := range stores {
+		store()
+	}
+	c.mu.Unlock()
+
+	c.notify(old, cur)
+	return nil
+}
+
+// watchConfig 监听配置文件所在的目录, 配置文件或匹配某一层的文件变化时重新加载
+// 监听目录而不是文件, 使编辑器以重命名方式保存、通配符新增文件时同样生效
+func (c *Config) watchConfig() error {
+	watcher, err := fsnotify.NewWatcher()
+	if err != nil {
+		return err
+	}
+
+	for _, dir := range c.watchDirs() {
+		if err := watcher.Add(dir); err != nil && !errors.Is(err, fs.ErrNotExist) {
+			_ = watcher.Close()
+			return err
+		}
+	}
+
+	go func() {
+		defer watcher.Close()
+		for {
+			select {
+			case <-c.ctx.Done():
 				return
+			case event, ok := <-watcher.Events:
+				if !ok {
+					return
+				}
+				if event.Has(fsnotify.Chmod) || !c.watches(event.Name) {
+					continue
+				}
+				_ = c.reload()
+			case err, ok := <-watcher.Errors:
+				if !ok {
+					return
+				}
+				_ = c.opts.errReadHandler(err)
 			}
 		}
-	})
-	c.v.WatchConfig()
+	}()
+	return nil
 }
 
-func (c *Config) watchRemote(ctx context.Context) {
-	if c.opts.remote == nil {
-		return
+func (c *Config) watchDirs() []string {
+	seen := make(map[string]bool)
+	var dirs []string
+	add := func(dir string) {
+		if dir == "" || seen[dir] {
+			return
+		}
+		seen[dir] = true
+		dirs = append(dirs, dir)
 	}
 
-	ticker := time.NewTicker(c.opts.watchInterval)
-	defer ticker.Stop()
+	c.mu.RLock()
+	for _, file := range c.files {
+		add(filepath.Dir(file))
+	}
+	c.mu.RUnlock()
+	if cf := c.opts.configFile; cf != nil && cf.data == nil {
+		if cf.file != "" {
+			add(filepath.Dir(cf.file))
+		}
+		for _, path := range cf.paths {
+			add(path)
+		}
+	}
+	for _, layer := range c.opts.layers {
+		add(layer.watchDir())
+	}
+	return dirs
+}
 
-	for {
-		select {
-		case <-ctx.Done():
-			return
-		case <-ticker.C:
-			c.mu.Lock()
-			if err := c.v.WatchRemoteConfig(); err != nil {
-				return
-			}
-			c.mu.Unlock()
+// watches 判断文件变化是否需要重新加载
+func (c *Config) watches(file string) bool {
+	file = filepath.Clean(file)
+
+	c.mu.RLock()
+	for _, f := range c.files {
+		if filepath.Clean(f) == file {
+			c.mu.RUnlock()
+			return true
+		}
+	}
+	c.mu.RUnlock()
+
+	if cf := c.opts.configFile; cf != nil && cf.data == nil {
+		if cf.file != "" && filepath.Clean(cf.file) == file {
+			return true
+		}
+		base := filepath.Base(file)
+		if cf.name != "" && strings.TrimSuffix(base, filepath.Ext(base)) == cf.name {
+			return true
+		}
+	}
+	for _, layer := range c.opts.layers {
+		if layer.match(file) {
+			return true
 		}
 	}
+	return false
+}
+
+// Close 停止监听配置文件与远程配置, 之后仍然可以读取最后一次加载的配置
+func (c *Config) Close() error {
+	c.cancel()
+	return nil
+}
+
+// Files 返回最近一次加载使用的配置文件, 按合并顺序排列
+func (c *Config) Files() []string {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return append([]string(nil), c.files...)
 }
 
 func (c *Config) Get(key string) any {
@@ -148,10 +365,16 @@ func (c *Config) Get(key string) any {
 	return c.v.Get(key)
 }
 
+func (c *Config) IsSet(key string) bool {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.v.IsSet(key)
+}
+
 func (c *Config) Unmarshal(target any) error {
 	c.mu.Lock()
 	defer c.mu.Unlock()
-	return c.v.Unmarshal(target)
+	return c.v.Unmarshal(target, decoderOption())
 }
 
 func (c *Config) AllSettings() map[string]any {
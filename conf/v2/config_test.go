@@ -0,0 +1,119 @@
+package confv2
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func writeFile(t *testing.T, path, content string) {
+	t.Helper()
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestConfigLayers(t *testing.T) {
+	dir := t.TempDir()
+	writeFile(t, filepath.Join(dir, "config.yaml"), "app: base\nserver:\n  host: localhost\n  port: 8080\ntags: [a]\n")
+	writeFile(t, filepath.Join(dir, "config.prod.yaml"), "server:\n  host: prod.example.com\ntags: [b]\n")
+	writeFile(t, filepath.Join(dir, "conf.d", "10-log.yaml"), "log:\n  level: info\n")
+	writeFile(t, filepath.Join(dir, "conf.d", "20-log.yaml"), "log:\n  level: warn\n")
+	t.Setenv("APP_ENV", "prod")
+
+	tests := []struct {
+		name  string
+		lists ListMerge
+		tags  []any
+	}{
+		{name: "replace", lists: ListReplace, tags: []any{"b"}},
+		{name: "append", lists: ListAppend, tags: []any{"a", "b"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c, err := Init().
+				WithConfigFilePath(filepath.Join(dir, "config.yaml"), "yaml").
+				WithConfigLayers(
+					ConfigLayer{Pattern: filepath.Join(dir, "config.$APP_ENV.yaml")},
+					ConfigLayer{Pattern: filepath.Join(dir, "conf.d", "*.yaml")},
+				).
+				WithListMerge(tt.lists).
+				Loading()
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if got := c.Get("server.host"); got != "prod.example.com" {
+				t.Errorf("server.host = %v", got)
+			}
+			if got := c.Get("server.port"); got != 8080 {
+				t.Errorf("server.port = %v", got)
+			}
+			if got := c.Get("log.level"); got != "warn" {
+				t.Errorf("log.level = %v", got)
+			}
+			if got := c.Get("tags"); !reflect.DeepEqual(got, tt.tags) {
+				t.Errorf("tags = %v, want %v", got, tt.tags)
+			}
+			if got := len(c.Files()); got != 4 {
+				t.Errorf("files = %v", c.Files())
+			}
+		})
+	}
+}
+
+func TestConfigLayers_Required(t *testing.T) {
+	dir := t.TempDir()
+
+	_, err := Init().
+		WithConfigLayers(
+			ConfigLayer{Pattern: filepath.Join(dir, "config.$UNSET_ENV.yaml")},
+			ConfigLayer{Pattern: filepath.Join(dir, "optional.yaml")},
+		).
+		Loading()
+	if err != nil {
+		t.Fatalf("optional layers: %v", err)
+	}
+
+	_, err = Init().
+		WithConfigLayers(ConfigLayer{Pattern: filepath.Join(dir, "conf.d", "*.yaml"), Required: true}).
+		Loading()
+	if !errors.Is(err, ErrConfigNotFound) {
+		t.Fatalf("required layer: got %v, want ErrConfigNotFound", err)
+	}
+}
+
+func TestConfigLayers_Watch(t *testing.T) {
+	dir := t.TempDir()
+	writeFile(t, filepath.Join(dir, "config.yaml"), "app: base\n")
+
+	c, err := Init().
+		WithConfigFilePath(filepath.Join(dir, "config.yaml"), "yaml").
+		WithConfigLayers(ConfigLayer{Pattern: filepath.Join(dir, "conf.d", "*.yaml")}).
+		WithWatch(true).
+		Loading()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	writeFile(t, filepath.Join(dir, "config.yaml"), "app: changed\n")
+	eventually(t, func() bool { return c.Get("app") == "changed" })
+}
+
+func eventually(t *testing.T, cond func() bool) {
+	t.Helper()
+	deadline := time.Now().Add(3 * time.Second)
+	for time.Now().Before(deadline) {
+		if cond() {
+			return
+		}
+		time.Sleep(20 * time.Millisecond)
+	}
+	t.Fatal("condition not met before deadline")
+}
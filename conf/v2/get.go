@@ -0,0 +1,93 @@
+package confv2
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+
+	"github.com/go-viper/mapstructure/v2"
+	"github.com/spf13/viper"
+)
+
+var ErrKeyNotFound = errors.New("config key not found")
+
+// KeyError 按类型读取配置失败, 包含配置项、期望的类型与配置中的原始值
+type KeyError struct {
+	Key   string
+	Type  reflect.Type
+	Value any
+	Err   error
+}
+
+func (e *KeyError) Error() string {
+	if errors.Is(e.Err, ErrKeyNotFound) {
+		return fmt.Sprintf("config key %q: %v", e.Key, e.Err)
+	}
+	return fmt.Sprintf("config key %q: cannot decode %T %v into %s: %v", e.Key, e.Value, e.Value, e.Type, e.Err)
+}
+
+func (e *KeyError) Unwrap() error {
+	return e.Err
+}
+
+// decodeHook Unmarshal 与 Get 共用的类型转换:
+// encoding.TextUnmarshaler(如 ByteSize)、time.Duration 与逗号分隔的切片
+func decodeHook() mapstructure.DecodeHookFunc {
+	return mapstructure.ComposeDecodeHookFunc(
+		mapstructure.TextUnmarshallerHookFunc(),
+		mapstructure.StringToTimeDurationHookFunc(),
+		mapstructure.StringToSliceHookFunc(","),
+	)
+}
+
+func decoderOption() viper.DecoderConfigOption {
+	return viper.DecodeHook(decodeHook())
+}
+
+func decode(input any, output any) error {
+	decoder, err := mapstructure.NewDecoder(&mapstructure.DecoderConfig{
+		DecodeHook:       decodeHook(),
+		WeaklyTypedInput: true,
+		Result:           output,
+	})
+	if err != nil {
+		return err
+	}
+	return decoder.Decode(input)
+}
+
+// Get 读取 key 并转换为 T, key 未设置时返回的 KeyError 包含 ErrKeyNotFound
+func Get[T any](c *Config, key string) (T, error) {
+	var out T
+	if !c.IsSet(key) {
+		return out, &KeyError{Key: key, Type: reflect.TypeOf(&out).Elem(), Err: ErrKeyNotFound}
+	}
+
+	raw := c.Get(key)
+	if v, ok := raw.(T); ok {
+		return v, nil
+	}
+	if err := decode(raw, &out); err != nil {
+		var zero T
+		return zero, &KeyError{Key: key, Type: reflect.TypeOf(&out).Elem(), Value: raw, Err: err}
+	}
+	return out, nil
+}
+
+// GetOr 读取 key 并转换为 T, key 未设置或无法转换时返回 def
+func GetOr[T any](c *Config, key string, def T) T {
+	v, err := Get[T](c, key)
+	if err != nil {
+		return def
+	}
+	return v
+}
+
+// MustGet 读取 key 并转换为 T, 失败时 panic
+func MustGet[T any](c *Config, key string) T {
+	v, err := Get[T](c, key)
+	if err != nil {
+		panic(err)
+	}
+	return v
+}
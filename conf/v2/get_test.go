@@ -0,0 +1,77 @@
+package confv2
+
+import (
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestGet(t *testing.T) {
+	c, err := Init().
+		WithConfigReader(strings.NewReader(`
+timeout: 1m30s
+max_body: 10MiB
+port: "8080"
+hosts: a,b
+labels:
+  env: prod
+server:
+  host: localhost
+  port: 9090
+`), "yaml").
+		Loading()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if got := MustGet[time.Duration](c, "timeout"); got != 90*time.Second {
+		t.Errorf("timeout = %v", got)
+	}
+	if got := MustGet[ByteSize](c, "max_body"); got != 10<<20 {
+		t.Errorf("max_body = %v", got)
+	}
+	if got := MustGet[int](c, "port"); got != 8080 {
+		t.Errorf("port = %v", got)
+	}
+	if got := MustGet[[]string](c, "hosts"); !reflect.DeepEqual(got, []string{"a", "b"}) {
+		t.Errorf("hosts = %v", got)
+	}
+	if got := MustGet[map[string]string](c, "labels"); got["env"] != "prod" {
+		t.Errorf("labels = %v", got)
+	}
+
+	type server struct {
+		Host string
+		Port int
+	}
+	if got := MustGet[server](c, "server"); got != (server{Host: "localhost", Port: 9090}) {
+		t.Errorf("server = %+v", got)
+	}
+
+	if got := GetOr(c, "missing", 3*time.Second); got != 3*time.Second {
+		t.Errorf("GetOr = %v", got)
+	}
+}
+
+func TestGet_Error(t *testing.T) {
+	c, err := Init().WithConfigReader(strings.NewReader("timeout: soon\n"), "yaml").Loading()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	_, err = Get[time.Duration](c, "timeout")
+	var keyErr *KeyError
+	if !errors.As(err, &keyErr) {
+		t.Fatalf("got %v, want *KeyError", err)
+	}
+	if keyErr.Key != "timeout" || keyErr.Type != reflect.TypeOf(time.Duration(0)) || keyErr.Value != "soon" {
+		t.Errorf("unexpected KeyError: %+v", keyErr)
+	}
+
+	_, err = Get[string](c, "missing")
+	if !errors.Is(err, ErrKeyNotFound) {
+		t.Errorf("got %v, want ErrKeyNotFound", err)
+	}
+}
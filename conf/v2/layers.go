@@ -0,0 +1,128 @@
+package confv2
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/spf13/viper"
+)
+
+// ConfigLayer 一层配置文件, Pattern 支持 $ENV 形式的环境变量与 filepath.Glob 通配符,
+// 例如 config.$ENV.yaml、conf.d/*.yaml；通配符匹配到的多个文件按文件名顺序合并
+type ConfigLayer struct {
+	Pattern string
+	// Type 配置文件类型, 为空时根据扩展名推断
+	Type string
+	// Required 为 true 时未匹配到文件返回 ErrConfigNotFound, 否则跳过该层
+	Required bool
+}
+
+// ListMerge 合并多层配置时列表的合并方式
+type ListMerge int
+
+const (
+	// ListReplace 后面的层替换整个列表
+	ListReplace ListMerge = iota
+	// ListAppend 后面的层追加到列表末尾
+	ListAppend
+)
+
+// expandPattern 展开 pattern 中的环境变量, 引用了未设置的环境变量时返回 false
+func expandPattern(pattern string) (string, bool) {
+	ok := true
+	expanded := os.Expand(pattern, func(key string) string {
+		val, set := os.LookupEnv(key)
+		if !set || val == "" {
+			ok = false
+		}
+		return val
+	})
+	return expanded, ok
+}
+
+// resolve 返回该层匹配到的文件
+func (l ConfigLayer) resolve() ([]string, error) {
+	pattern, ok := expandPattern(l.Pattern)
+	if !ok {
+		if l.Required {
+			return nil, fmt.Errorf("%w: %s references an unset environment variable", ErrConfigNotFound, l.Pattern)
+		}
+		return nil, nil
+	}
+
+	files, err := filepath.Glob(pattern)
+	if err != nil {
+		return nil, fmt.Errorf("invalid config layer %q: %w", l.Pattern, err)
+	}
+	if len(files) == 0 && l.Required {
+		return nil, fmt.Errorf("%w: %s", ErrConfigNotFound, pattern)
+	}
+	return files, nil
+}
+
+// watchDir 返回需要监听的目录, 目录部分包含通配符或环境变量未设置时返回空
+func (l ConfigLayer) watchDir() string {
+	pattern, ok := expandPattern(l.Pattern)
+	if !ok {
+		return ""
+	}
+	dir := filepath.Dir(pattern)
+	if strings.ContainsAny(dir, "*?[") {
+		return ""
+	}
+	return dir
+}
+
+// match 判断 file 是否属于该层
+func (l ConfigLayer) match(file string) bool {
+	pattern, ok := expandPattern(l.Pattern)
+	if !ok {
+		return false
+	}
+	matched, _ := filepath.Match(filepath.Clean(pattern), filepath.Clean(file))
+	return matched
+}
+
+// readFile 读取单个配置文件
+func readFile(file, typ string) (map[string]any, error) {
+	v := viper.New()
+	v.SetConfigFile(file)
+	if typ != "" {
+		v.SetConfigType(typ)
+	}
+	if err := v.ReadInConfig(); err != nil {
+		return nil, err
+	}
+	return v.AllSettings(), nil
+}
+
+// mergeSettings 将 src 深度合并到 dst, src 中的值优先
+func mergeSettings(dst, src map[string]any, lists ListMerge) map[string]any {
+	if dst == nil {
+		dst = make(map[string]any, len(src))
+	}
+	for k, sv := range src {
+		dv, ok := dst[k]
+		if !ok {
+			dst[k] = sv
+			continue
+		}
+
+		switch s := sv.(type) {
+		case map[string]any:
+			if d, ok := dv.(map[string]any); ok {
+				dst[k] = mergeSettings(d, s, lists)
+				continue
+			}
+		case []any:
+			if d, ok := dv.([]any); ok && lists == ListAppend {
+				dst[k] = append(append(make([]any, 0, len(d)+len(s)), d...), s...)
+				continue
+			}
+		}
+		dst[k] = sv
+	}
+	return dst
+}
@@ -9,7 +9,6 @@ import (
 	"time"
 
 	"github.com/spf13/pflag"
-	"github.com/spf13/viper"
 )
 
 var (
@@ -30,9 +29,14 @@ type Options struct {
 	envReplacer *strings.Replacer
 	envBinds    []string
 
-	dotEnv     *FileConfig
-	configFile *FileConfig
-	remote     *RemoteConfig
+	dotEnv         *FileConfig
+	configFile     *FileConfig
+	configOptional bool
+	layers         []ConfigLayer
+	listMerge      ListMerge
+	remote         *RemoteConfig
+	remoteSource   RemoteSource
+	remoteOpts     RemoteOptions
 
 	defaults map[string]any
 
@@ -50,7 +54,10 @@ type FileConfig struct {
 	name  string
 	typ   string
 	paths []string
+	file  string
 	io    io.Reader
+	// data 为 io 中读取的内容, 使重新加载时可以再次解析
+	data []byte
 }
 
 type RemoteConfig struct {
@@ -82,29 +89,54 @@ func (b *ConfigBuilder) Loading() (*Config, error) {
 		return nil, b.err
 	}
 
-	v := viper.New()
-	c := &Config{
-		v:    v,
-		opts: b.opts,
+	if cf := b.opts.configFile; cf != nil && cf.io != nil && cf.data == nil {
+		data, err := io.ReadAll(cf.io)
+		if err != nil {
+			return nil, b.opts.errReadHandler(ErrReaderIO)
+		}
+		cf.data = data
 	}
 
-	c.setDefault()
-	if err := c.loadConfigFile(); err != nil {
+	c := &Config{opts: b.opts}
+	c.ctx, c.cancel = context.WithCancel(context.Background())
+	if c.opts.remoteSource != nil {
+		c.remote = &remoteState{src: c.opts.remoteSource, opts: c.opts.remoteOpts.withDefaults()}
+		// 使用缓存时仅报告错误, 没有可用的远程配置时加载失败
+		if err := c.remote.fetch(c.ctx); err != nil {
+			if c.remote.current() == nil {
+				c.cancel()
+				return nil, err
+			}
+			_ = c.opts.errReadHandler(err)
+		}
+	}
+
+	v, files, err := c.load()
+	if err != nil {
+		c.cancel()
 		return nil, err
 	}
-	if err := c.loadDotEnv(); err != nil {
+	target, err := c.decodeTarget(v)
+	if err != nil {
+		c.cancel()
 		return nil, err
 	}
-	c.setupEnv()
-	c.bindPFlags()
-	c.setArgs()
-	c.set()
+	c.v, c.files = v, files
+	c.applyTarget(target)
+	if c.remote != nil {
+		if err := c.remote.writeCache(); err != nil {
+			_ = c.opts.errReadHandler(fmt.Errorf("%w: write cache: %v", ErrRemoteConfig, err))
+		}
+	}
 
 	if c.opts.watching {
-		c.watchConfig()
+		if err := c.watchConfig(); err != nil {
+			c.cancel()
+			return nil, err
+		}
 	}
-	if c.opts.watchRemote && c.opts.remote != nil {
-		go c.watchRemote(context.Background())
+	if c.opts.watchRemote && c.remote != nil {
+		go c.watchRemote(c.ctx)
 	}
 
 	return c, nil
@@ -165,6 +197,35 @@ func (b *ConfigBuilder) WithConfigFile(name, typ string, paths ...string) *Confi
 	return b
 }
 
+// WithConfigFilePath 直接使用指定路径的配置文件, 不再按名称搜索
+// typ 为空时根据文件扩展名推断
+func (b *ConfigBuilder) WithConfigFilePath(file, typ string) *ConfigBuilder {
+	b.opts.configFile = &FileConfig{
+		file: file,
+		typ:  typ,
+	}
+	return b
+}
+
+// WithConfigOptional 允许按名称搜索的配置文件不存在, 此时仅使用其他配置来源
+func (b *ConfigBuilder) WithConfigOptional(optional bool) *ConfigBuilder {
+	b.opts.configOptional = optional
+	return b
+}
+
+// WithConfigLayers 在配置文件之后按顺序追加多层配置, 后面的层覆盖前面的层,
+// 例如 config.yaml、config.$ENV.yaml、conf.d/*.yaml
+func (b *ConfigBuilder) WithConfigLayers(layers ...ConfigLayer) *ConfigBuilder {
+	b.opts.layers = append(b.opts.layers, layers...)
+	return b
+}
+
+// WithListMerge 指定多层配置中列表的合并方式, 默认为 ListReplace
+func (b *ConfigBuilder) WithListMerge(mode ListMerge) *ConfigBuilder {
+	b.opts.listMerge = mode
+	return b
+}
+
 func (b *ConfigBuilder) WithConfigReader(r io.Reader, typ string) *ConfigBuilder {
 	if b.opts.configFile == nil {
 		b.opts.configFile = &FileConfig{}
@@ -179,6 +240,7 @@ func (b *ConfigBuilder) WithDefault(key string, value any) *ConfigBuilder {
 	return b
 }
 
+// Deprecated: 依赖未启用的 viper 远程配置, 从未生效, 使用 WithRemoteSource
 func (b *ConfigBuilder) WithRemote(provider, endpoint, path, typ string) *ConfigBuilder {
 	b.opts.remote = &RemoteConfig{
 		provider: provider,
@@ -189,11 +251,24 @@ func (b *ConfigBuilder) WithRemote(provider, endpoint, path, typ string) *Config
 	return b
 }
 
+// WithRemoteSource 从 src 加载远程配置, 优先级高于配置文件, 低于 dotenv 与环境变量
+// 启动时获取失败则使用 RemoteOptions.CacheFile 中最近一次有效的配置,
+// 配合 WithRemoteWatch 在远程配置变化时重新加载
+func (b *ConfigBuilder) WithRemoteSource(src RemoteSource, opts RemoteOptions) *ConfigBuilder {
+	b.opts.remoteSource = src
+	b.opts.remoteOpts = opts
+	return b
+}
+
 func (b *ConfigBuilder) WithFlags(flags ...*pflag.FlagSet) *ConfigBuilder {
 	b.opts.flags = append(b.opts.flags, flags...)
 	return b
 }
 
+// WithUnmarshal 加载后反序列化到 target, target 必须是指针
+// 结构体字段的 default 标签作为默认值, validate 标签在反序列化后校验,
+// 校验失败时 Loading 返回 ValidationError, 重新加载时保留原有配置
+// 开启 WithWatch 时重新加载会写入 target, 并发读取时应使用 NewSnapshot
 func (b *ConfigBuilder) WithUnmarshal(target any) *ConfigBuilder {
 	b.opts.unmarshalTo = target
 	return b
@@ -204,6 +279,7 @@ func (b *ConfigBuilder) WithWatch(enable bool) *ConfigBuilder {
 	return b
 }
 
+// WithRemoteWatch 监听 WithRemoteSource 的变化, interval 为两次 Watch 之间的最小间隔, 默认 5s
 func (b *ConfigBuilder) WithRemoteWatch(enable bool, interval time.Duration) *ConfigBuilder {
 	b.opts.watchRemote = enable
 	b.opts.watchInterval = interval
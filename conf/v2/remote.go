@@ -0,0 +1,211 @@
+package confv2
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/spf13/viper"
+)
+
+// RemoteData 远程配置的一个版本
+type RemoteData struct {
+	Data []byte
+	// Type 配置格式, 如 yaml、json
+	Type string
+	// Version 用于判断配置是否变化, 如 HTTP 的 ETag 或内容摘要
+	Version string
+}
+
+// RemoteSource 远程配置来源
+type RemoteSource interface {
+	// Fetch 获取当前的配置
+	Fetch(ctx context.Context) (*RemoteData, error)
+	// Watch 阻塞直到配置的版本不再是 version 或 ctx 结束,
+	// 返回新的配置; 等待期间没有变化时可以返回 nil, nil
+	Watch(ctx context.Context, version string) (*RemoteData, error)
+}
+
+// RemoteOptions 远程配置的加载选项, 零值使用默认值
+type RemoteOptions struct {
+	// Timeout 单次 Fetch 的超时时间, 默认 10s
+	Timeout time.Duration
+	// WatchTimeout 单次 Watch 的超时时间, 超时视为没有变化, 默认 1m
+	WatchTimeout time.Duration
+	// MinBackoff/MaxBackoff Watch 失败后重试的等待时间, 每次失败翻倍, 默认 1s/1m
+	MinBackoff time.Duration
+	MaxBackoff time.Duration
+	// CacheFile 最近一次成功加载的远程配置的缓存文件,
+	// 启动时远程配置不可用则使用缓存, 为空时不缓存
+	CacheFile string
+}
+
+const defaultRemoteInterval = 5 * time.Second
+
+func (o RemoteOptions) withDefaults() RemoteOptions {
+	if o.Timeout <= 0 {
+		o.Timeout = 10 * time.Second
+	}
+	if o.WatchTimeout <= 0 {
+		o.WatchTimeout = time.Minute
+	}
+	if o.MinBackoff <= 0 {
+		o.MinBackoff = time.Second
+	}
+	if o.MaxBackoff < o.MinBackoff {
+		o.MaxBackoff = max(time.Minute, o.MinBackoff)
+	}
+	return o
+}
+
+// remoteState 远程配置的当前版本, 文件变化引起的重新加载复用该版本而不重新获取
+type remoteState struct {
+	src  RemoteSource
+	opts RemoteOptions
+
+	mu   sync.Mutex
+	data *RemoteData
+}
+
+func (r *remoteState) current() *RemoteData {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.data
+}
+
+func (r *remoteState) swap(data *RemoteData) *RemoteData {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	old := r.data
+	r.data = data
+	return old
+}
+
+// fetch 获取远程配置, 失败时使用缓存文件, 缓存也不可用时返回 ErrRemoteConfig
+func (r *remoteState) fetch(ctx context.Context) error {
+	ctx, cancel := context.WithTimeout(ctx, r.opts.Timeout)
+	defer cancel()
+
+	data, err := r.src.Fetch(ctx)
+	if err == nil {
+		r.swap(data)
+		return nil
+	}
+
+	cached, cerr := r.readCache()
+	if cerr != nil {
+		return fmt.Errorf("%w: %v", ErrRemoteConfig, err)
+	}
+	r.swap(cached)
+	return fmt.Errorf("%w: %v, using cached config %s", ErrRemoteConfig, err, r.opts.CacheFile)
+}
+
+func (r *remoteState) settings() (map[string]any, error) {
+	data := r.current()
+	if data == nil {
+		return nil, nil
+	}
+
+	v := viper.New()
+	v.SetConfigType(data.Type)
+	if err := v.ReadConfig(bytes.NewReader(data.Data)); err != nil {
+		return nil, fmt.Errorf("%w: version %s: %v", ErrRemoteConfig, data.Version, err)
+	}
+	return v.AllSettings(), nil
+}
+
+func (r *remoteState) readCache() (*RemoteData, error) {
+	if r.opts.CacheFile == "" {
+		return nil, errors.New("no cache file")
+	}
+	raw, err := os.ReadFile(r.opts.CacheFile)
+	if err != nil {
+		return nil, err
+	}
+	var data RemoteData
+	if err := json.Unmarshal(raw, &data); err != nil {
+		return nil, err
+	}
+	return &data, nil
+}
+
+// writeCache 以重命名的方式写入缓存, 只缓存校验通过的配置
+func (r *remoteState) writeCache() error {
+	data := r.current()
+	if r.opts.CacheFile == "" || data == nil {
+		return nil
+	}
+
+	raw, err := json.Marshal(data)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(r.opts.CacheFile), 0o755); err != nil {
+		return err
+	}
+	tmp := r.opts.CacheFile + ".tmp"
+	if err := os.WriteFile(tmp, raw, 0o600); err != nil {
+		return err
+	}
+	return os.Rename(tmp, r.opts.CacheFile)
+}
+
+// watchRemote 持续等待远程配置变化并重新加载, 直到 ctx 结束
+// 重新加载失败时恢复到之前的版本, 使之后文件变化引起的重新加载仍然使用有效的远程配置
+func (c *Config) watchRemote(ctx context.Context) {
+	r := c.remote
+	interval := c.opts.watchInterval
+	if interval <= 0 {
+		interval = defaultRemoteInterval
+	}
+	backoff := r.opts.MinBackoff
+
+	for {
+		start := time.Now()
+		wctx, cancel := context.WithTimeout(ctx, r.opts.WatchTimeout)
+		data, err := r.src.Watch(wctx, r.current().version())
+		cancel()
+		if ctx.Err() != nil {
+			return
+		}
+
+		wait := interval - time.Since(start)
+		switch {
+		case err != nil && !errors.Is(err, context.DeadlineExceeded):
+			_ = c.opts.errReadHandler(fmt.Errorf("%w: %v", ErrRemoteConfig, err))
+			wait = backoff
+			backoff = min(backoff*2, r.opts.MaxBackoff)
+		case data != nil && data.Version != r.current().version():
+			backoff = r.opts.MinBackoff
+			old := r.swap(data)
+			if err := c.reload(); err != nil {
+				r.swap(old)
+			} else if err := r.writeCache(); err != nil {
+				_ = c.opts.errReadHandler(fmt.Errorf("%w: write cache: %v", ErrRemoteConfig, err))
+			}
+		default:
+			backoff = r.opts.MinBackoff
+		}
+
+		if wait > 0 {
+			select {
+			case <-ctx.Done():
+				return
+			case <-time.After(wait):
+			}
+		}
+	}
+}
+
+func (d *RemoteData) version() string {
+	if d == nil {
+		return ""
+	}
+	return d.Version
+}
@@ -0,0 +1,118 @@
+package confv2
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/fsnotify/fsnotify"
+	"github.com/spf13/viper"
+)
+
+// DirSource 读取目录中全部配置文件的 RemoteSource, 适用于挂载的配置目录(如 Kubernetes ConfigMap)
+// 文件按文件名顺序深度合并, 以 "." 开头的文件与目录被忽略, 内容变化时 Watch 返回新的配置
+type DirSource struct {
+	Dir string
+	// Exts 读取的扩展名, 默认 .yaml、.yml、.json、.toml
+	Exts []string
+	// Lists 列表的合并方式
+	Lists ListMerge
+}
+
+// NewDirSource 创建 DirSource
+func NewDirSource(dir string) *DirSource {
+	return &DirSource{Dir: dir}
+}
+
+// Fetch implements RemoteSource.
+func (s *DirSource) Fetch(ctx context.Context) (*RemoteData, error) {
+	files, err := s.files()
+	if err != nil {
+		return nil, err
+	}
+
+	var settings map[string]any
+	h := sha256.New()
+	for _, file := range files {
+		raw, err := os.ReadFile(file)
+		if err != nil {
+			return nil, err
+		}
+		h.Write([]byte(filepath.Base(file)))
+		h.Write(raw)
+
+		v := viper.New()
+		v.SetConfigFile(file)
+		if err := v.ReadInConfig(); err != nil {
+			return nil, err
+		}
+		settings = mergeSettings(settings, v.AllSettings(), s.Lists)
+	}
+
+	data, err := json.Marshal(settings)
+	if err != nil {
+		return nil, err
+	}
+	return &RemoteData{Data: data, Type: "json", Version: hex.EncodeToString(h.Sum(nil))}, nil
+}
+
+// Watch implements RemoteSource.
+// 监听目录而不是文件, 使原子替换的符号链接(ConfigMap 的 ..data)同样生效
+func (s *DirSource) Watch(ctx context.Context, version string) (*RemoteData, error) {
+	watcher, err := fsnotify.NewWatcher()
+	if err != nil {
+		return nil, err
+	}
+	defer watcher.Close()
+	if err := watcher.Add(s.Dir); err != nil {
+		return nil, err
+	}
+
+	// 开始监听之前可能已经发生了变化
+	if data, err := s.Fetch(ctx); err != nil || data.Version != version {
+		return data, err
+	}
+	for {
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case err := <-watcher.Errors:
+			return nil, err
+		case <-watcher.Events:
+			data, err := s.Fetch(ctx)
+			if err != nil || data.Version != version {
+				return data, err
+			}
+		}
+	}
+}
+
+func (s *DirSource) files() ([]string, error) {
+	exts := s.Exts
+	if len(exts) == 0 {
+		exts = []string{".yaml", ".yml", ".json", ".toml"}
+	}
+
+	entries, err := os.ReadDir(s.Dir)
+	if err != nil {
+		return nil, err
+	}
+	var files []string
+	for _, e := range entries {
+		if strings.HasPrefix(e.Name(), ".") || !slices.Contains(exts, filepath.Ext(e.Name())) {
+			continue
+		}
+		path := filepath.Join(s.Dir, e.Name())
+		// 跟随符号链接, 忽略目录
+		if info, err := os.Stat(path); err != nil || info.IsDir() {
+			continue
+		}
+		files = append(files, path)
+	}
+	return files, nil
+}
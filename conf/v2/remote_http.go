@@ -0,0 +1,119 @@
+package confv2
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"path"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// HTTPSource 通过 HTTP GET 获取配置的 RemoteSource
+// Watch 使用 If-None-Match 携带当前的 ETag, 并通过 "Prefer: wait=N" 请求服务端长轮询,
+// 服务端返回 304 表示没有变化; 不支持长轮询的服务端立即返回时按 WithRemoteWatch 的间隔轮询
+type HTTPSource struct {
+	URL string
+	// Type 配置格式, 为空时根据 Content-Type 或 URL 的扩展名推断
+	Type   string
+	Header http.Header
+	Client *http.Client
+	// Wait 长轮询的等待时间, 默认 30s, 应小于 RemoteOptions.WatchTimeout
+	Wait time.Duration
+}
+
+// NewHTTPSource 创建 HTTPSource, typ 为空时自动推断
+func NewHTTPSource(url, typ string) *HTTPSource {
+	return &HTTPSource{URL: url, Type: typ}
+}
+
+// Fetch implements RemoteSource.
+func (s *HTTPSource) Fetch(ctx context.Context) (*RemoteData, error) {
+	data, _, err := s.get(ctx, "", 0)
+	return data, err
+}
+
+// Watch implements RemoteSource.
+func (s *HTTPSource) Watch(ctx context.Context, version string) (*RemoteData, error) {
+	wait := s.Wait
+	if wait <= 0 {
+		wait = 30 * time.Second
+	}
+	data, notModified, err := s.get(ctx, version, wait)
+	if err != nil || notModified || data.Version == version {
+		return nil, err
+	}
+	return data, nil
+}
+
+func (s *HTTPSource) get(ctx context.Context, etag string, wait time.Duration) (*RemoteData, bool, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, s.URL, nil)
+	if err != nil {
+		return nil, false, err
+	}
+	for k, vals := range s.Header {
+		for _, v := range vals {
+			req.Header.Add(k, v)
+		}
+	}
+	if etag != "" {
+		req.Header.Set("If-None-Match", etag)
+	}
+	if wait > 0 {
+		req.Header.Set("Prefer", "wait="+strconv.Itoa(int(wait.Seconds())))
+	}
+
+	client := s.Client
+	if client == nil {
+		client = http.DefaultClient
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, false, err
+	}
+	defer resp.Body.Close()
+
+	switch resp.StatusCode {
+	case http.StatusOK:
+	case http.StatusNotModified:
+		return nil, true, nil
+	default:
+		return nil, false, fmt.Errorf("GET %s: unexpected status %s", s.URL, resp.Status)
+	}
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, false, err
+	}
+	version := resp.Header.Get("ETag")
+	if version == "" {
+		sum := sha256.Sum256(body)
+		version = hex.EncodeToString(sum[:])
+	}
+	return &RemoteData{Data: body, Type: s.configType(resp), Version: version}, false, nil
+}
+
+func (s *HTTPSource) configType(resp *http.Response) string {
+	if s.Type != "" {
+		return s.Type
+	}
+	if mt, _, err := mime.ParseMediaType(resp.Header.Get("Content-Type")); err == nil {
+		switch {
+		case strings.HasSuffix(mt, "json"):
+			return "json"
+		case strings.HasSuffix(mt, "yaml"):
+			return "yaml"
+		case strings.HasSuffix(mt, "toml"):
+			return "toml"
+		}
+	}
+	if ext := strings.TrimPrefix(path.Ext(resp.Request.URL.Path), "."); ext != "" {
+		return ext
+	}
+	return "json"
+}
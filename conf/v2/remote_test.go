@@ -0,0 +1,177 @@
+package confv2
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// remoteServer 支持 ETag 与长轮询的配置服务
+type remoteServer struct {
+	mu      sync.Mutex
+	version int
+	body    string
+	changed chan struct{}
+	fail    bool
+}
+
+func newRemoteServer(body string) *remoteServer {
+	return &remoteServer{version: 1, body: body, changed: make(chan struct{})}
+}
+
+func (s *remoteServer) set(body string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.version++
+	s.body = body
+	close(s.changed)
+	s.changed = make(chan struct{})
+}
+
+func (s *remoteServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	etag, body, changed, fail := strconv.Quote(strconv.Itoa(s.version)), s.body, s.changed, s.fail
+	s.mu.Unlock()
+
+	if fail {
+		http.Error(w, "unavailable", http.StatusServiceUnavailable)
+		return
+	}
+	if r.Header.Get("If-None-Match") == etag {
+		select {
+		case <-changed:
+			s.ServeHTTP(w, r)
+		case <-time.After(time.Second):
+			w.WriteHeader(http.StatusNotModified)
+		case <-r.Context().Done():
+		}
+		return
+	}
+	w.Header().Set("ETag", etag)
+	w.Header().Set("Content-Type", "application/yaml")
+	_, _ = w.Write([]byte(body))
+}
+
+func TestHTTPSource(t *testing.T) {
+	srv := newRemoteServer("name: remote\nserver:\n  port: 80\n")
+	ts := httptest.NewServer(srv)
+	defer ts.Close()
+
+	cache := filepath.Join(t.TempDir(), "remote.json")
+	c, err := Init().
+		WithConfigReader(strings.NewReader("name: local\nserver:\n  host: example.com\n"), "yaml").
+		WithRemoteSource(NewHTTPSource(ts.URL, ""), RemoteOptions{CacheFile: cache}).
+		WithRemoteWatch(true, 10*time.Millisecond).
+		Loading()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer c.Close()
+
+	snap, err := NewSnapshot[testAppConfig](c)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := snap.Load(); got.Name != "remote" || got.Server.Host != "example.com" || got.Server.Port != 80 {
+		t.Fatalf("snapshot = %+v", got)
+	}
+
+	srv.set("name: remote\nserver:\n  port: 8080\n")
+	eventually(t, func() bool { return snap.Load().Server.Port == 8080 })
+	eventually(t, func() bool {
+		raw, err := os.ReadFile(cache)
+		return err == nil && len(raw) > 0 && c.Get("server.port") == 8080
+	})
+
+	// 无效的远程配置不会替换当前配置
+	srv.set("name: remote\nserver:\n  port: 0\n")
+	time.Sleep(200 * time.Millisecond)
+	if got := snap.Load().Server.Port; got != 8080 {
+		t.Errorf("server.port = %d, invalid remote config should be rejected", got)
+	}
+}
+
+func TestHTTPSource_Cache(t *testing.T) {
+	srv := newRemoteServer("name: cached\n")
+	ts := httptest.NewServer(srv)
+	defer ts.Close()
+
+	cache := filepath.Join(t.TempDir(), "remote.json")
+	src := NewHTTPSource(ts.URL, "yaml")
+	if _, err := Init().WithRemoteSource(src, RemoteOptions{CacheFile: cache}).Loading(); err != nil {
+		t.Fatal(err)
+	}
+
+	srv.mu.Lock()
+	srv.fail = true
+	srv.mu.Unlock()
+
+	var reported error
+	c, err := Init().
+		WithRemoteSource(src, RemoteOptions{CacheFile: cache}).
+		WithErrReadHandler(func(err error) error {
+			reported = err
+			return err
+		}).
+		Loading()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := c.Get("name"); got != "cached" {
+		t.Errorf("name = %v, want value from cache", got)
+	}
+	if !errors.Is(reported, ErrRemoteConfig) {
+		t.Errorf("reported error = %v, want ErrRemoteConfig", reported)
+	}
+
+	_, err = Init().WithRemoteSource(src, RemoteOptions{}).Loading()
+	if !errors.Is(err, ErrRemoteConfig) {
+		t.Errorf("got %v, want ErrRemoteConfig without cache", err)
+	}
+}
+
+func TestDirSource(t *testing.T) {
+	dir := t.TempDir()
+	writeFile(t, filepath.Join(dir, "10-base.yaml"), "name: base\nserver:\n  port: 80\n")
+	writeFile(t, filepath.Join(dir, "20-override.json"), `{"server": {"port": 8080}}`)
+	writeFile(t, filepath.Join(dir, ".hidden.yaml"), "name: hidden\n")
+
+	src := NewDirSource(dir)
+	c, err := Init().WithRemoteSource(src, RemoteOptions{}).Loading()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if c.Get("name") != "base" || c.Get("server.port") != float64(8080) {
+		t.Fatalf("settings = %v", c.AllSettings())
+	}
+
+	data, err := src.Fetch(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+	done := make(chan *RemoteData, 1)
+	go func() {
+		next, err := src.Watch(ctx, data.Version)
+		if err != nil {
+			t.Error(err)
+		}
+		done <- next
+	}()
+
+	time.Sleep(50 * time.Millisecond)
+	writeFile(t, filepath.Join(dir, "30-name.yaml"), "name: changed\n")
+	next := <-done
+	if next == nil || next.Version == data.Version {
+		t.Fatalf("Watch did not report the change: %+v", next)
+	}
+}
@@ -0,0 +1,83 @@
+package confv2
+
+import (
+	"reflect"
+	"slices"
+	"sync/atomic"
+
+	"github.com/spf13/viper"
+)
+
+// Snapshot 类型化的配置快照
+// 每次重新加载都会反序列化到一个新的 T 并校验, 通过后以原子操作替换,
+// 失败时保留上一次的快照并通过 errReadHandler 报告错误
+type Snapshot[T any] struct {
+	p atomic.Pointer[T]
+}
+
+type snapshotter interface {
+	targetType() reflect.Type
+	// build 反序列化并校验, 返回替换快照的函数
+	build(v *viper.Viper) (func(), error)
+}
+
+// NewSnapshot 从 c 的当前配置创建快照, 并在 c 每次重新加载后更新
+// T 的 default 与 validate 标签与 WithUnmarshal 的规则相同
+func NewSnapshot[T any](c *Config) (*Snapshot[T], error) {
+	s := &Snapshot[T]{}
+
+	// 持有 reloadMu, 避免并发的重新加载在注册之后、首个快照存储之前完成,
+	// 使较旧的配置覆盖较新的快照
+	c.reloadMu.Lock()
+	defer c.reloadMu.Unlock()
+
+	// 先注册, 使 T 的 default 标签参与之后的每次加载;
+	// 当前配置中尚未包含这些默认值, 因此补充到当前的 viper 实例后再构建首个快照
+	c.snapMu.Lock()
+	c.snapshots = append(c.snapshots, s)
+	c.snapMu.Unlock()
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	defaults := make(map[string]string)
+	tagDefaults(s.targetType(), "", defaults)
+	for k, val := range defaults {
+		c.v.SetDefault(k, val)
+	}
+	store, err := s.build(c.v)
+	if err != nil {
+		c.removeSnapshot(s)
+		return nil, err
+	}
+	store()
+	return s, nil
+}
+
+// Load 返回当前的快照, 调用方不能修改返回的值
+func (s *Snapshot[T]) Load() *T {
+	return s.p.Load()
+}
+
+func (s *Snapshot[T]) targetType() reflect.Type {
+	return reflect.TypeOf((*T)(nil)).Elem()
+}
+
+func (s *Snapshot[T]) build(v *viper.Viper) (func(), error) {
+	t := new(T)
+	if err := decodeValidated(v, t); err != nil {
+		return nil, err
+	}
+	return func() { s.p.Store(t) }, nil
+}
+
+func (c *Config) snapshotList() []snapshotter {
+	c.snapMu.Lock()
+	defer c.snapMu.Unlock()
+	return slices.Clone(c.snapshots)
+}
+
+func (c *Config) removeSnapshot(s snapshotter) {
+	c.snapMu.Lock()
+	defer c.snapMu.Unlock()
+	c.snapshots = slices.DeleteFunc(c.snapshots, func(x snapshotter) bool { return x == s })
+}
@@ -0,0 +1,99 @@
+package confv2
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestSnapshot(t *testing.T) {
+	dir := t.TempDir()
+	file := filepath.Join(dir, "config.yaml")
+	writeFile(t, file, "name: app\nserver:\n  port: 80\n")
+
+	reloadErrs := make(chan error, 8)
+	c, err := Init().
+		WithConfigFilePath(file, "yaml").
+		WithWatch(true).
+		WithErrReadHandler(func(err error) error {
+			reloadErrs <- err
+			return err
+		}).
+		Loading()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	snap, err := NewSnapshot[testAppConfig](c)
+	if err != nil {
+		t.Fatal(err)
+	}
+	first := snap.Load()
+	if first.Name != "app" || first.Server.Port != 80 || first.Server.Host != "localhost" {
+		t.Fatalf("snapshot = %+v", first)
+	}
+
+	// 并发读取与重新加载不需要加锁
+	stop := make(chan struct{})
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		for {
+			select {
+			case <-stop:
+				return
+			default:
+				_ = snap.Load().Server.Port
+			}
+		}
+	}()
+	defer func() {
+		close(stop)
+		wg.Wait()
+	}()
+
+	replaceFile(t, file, "name: app\nserver:\n  port: 8080\n")
+	eventually(t, func() bool { return snap.Load().Server.Port == 8080 })
+	if first.Server.Port != 80 {
+		t.Errorf("previous snapshot was modified: %+v", first)
+	}
+
+	// 无效配置不替换快照
+	good := snap.Load()
+	replaceFile(t, file, "server:\n  port: 0\n")
+	select {
+	case err := <-reloadErrs:
+		if !errors.Is(err, ErrInvalidConfig) {
+			t.Fatalf("reload error = %v", err)
+		}
+	case <-time.After(3 * time.Second):
+		t.Fatal("invalid config was not rejected")
+	}
+	if snap.Load() != good {
+		t.Errorf("snapshot should be kept after a failed reload")
+	}
+}
+
+func TestSnapshot_Invalid(t *testing.T) {
+	c, err := Init().WithSet("server.port", 0).Loading()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := NewSnapshot[testAppConfig](c); !errors.Is(err, ErrInvalidConfig) {
+		t.Fatalf("got %v, want ErrInvalidConfig", err)
+	}
+}
+
+// replaceFile 以重命名的方式原子替换文件, 避免重新加载时读到写入一半的内容
+func replaceFile(t *testing.T, file, content string) {
+	t.Helper()
+	tmp := filepath.Join(t.TempDir(), filepath.Base(file))
+	writeFile(t, tmp, content)
+	if err := os.Rename(tmp, file); err != nil {
+		t.Fatal(err)
+	}
+}
@@ -0,0 +1,180 @@
+package confv2
+
+import (
+	"encoding"
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/spf13/viper"
+)
+
+var ErrInvalidConfig = errors.New("invalid config")
+
+// FieldError 一个未通过校验的配置项
+type FieldError struct {
+	// Key 配置项的完整路径, 如 server.port
+	Key string
+	// Rule 未通过的规则, 如 required、min=1
+	Rule  string
+	Value any
+}
+
+func (e FieldError) String() string {
+	if e.Rule == "required" {
+		return e.Key + ": required"
+	}
+	return fmt.Sprintf("%s: failed %q (got %v)", e.Key, e.Rule, e.Value)
+}
+
+// ValidationError 汇总全部未通过 validate 标签校验的配置项
+type ValidationError struct {
+	Fields []FieldError
+}
+
+func (e *ValidationError) Error() string {
+	msgs := make([]string, 0, len(e.Fields))
+	for _, f := range e.Fields {
+		msgs = append(msgs, f.String())
+	}
+	return ErrInvalidConfig.Error() + ": " + strings.Join(msgs, "; ")
+}
+
+func (e *ValidationError) Unwrap() error {
+	return ErrInvalidConfig
+}
+
+var textUnmarshalerType = reflect.TypeOf((*encoding.TextUnmarshaler)(nil)).Elem()
+
+// configKey 返回字段对应的 mapstructure 键以及是否为 squash, 与 Unmarshal 的规则一致
+func configKey(field reflect.StructField) (string, bool) {
+	name, opts, _ := strings.Cut(field.Tag.Get("mapstructure"), ",")
+	squash := strings.Contains(","+opts+",", ",squash,")
+	if name == "" {
+		name = strings.ToLower(field.Name)
+	}
+	return name, squash
+}
+
+// tagDefaults 收集 default 标签, 键为 mapstructure 路径
+//
+//	default:"8080"       基本类型
+//	default:"30s"        time.Duration
+//	default:"a,b"        逗号分隔的切片
+//
+// 与 WithDefault 一样作为最低优先级的默认值, 任何配置来源设置该键时都会被覆盖
+func tagDefaults(t reflect.Type, prefix string, out map[string]string) {
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	if t.Kind() != reflect.Struct {
+		return
+	}
+
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		if !field.IsExported() || field.Tag.Get("mapstructure") == "-" {
+			continue
+		}
+
+		key, squash := configKey(field)
+		if prefix != "" {
+			key = prefix + "." + key
+		}
+		if def, ok := field.Tag.Lookup("default"); ok {
+			out[key] = def
+			continue
+		}
+
+		ft := field.Type
+		for ft.Kind() == reflect.Pointer {
+			ft = ft.Elem()
+		}
+		if ft.Kind() != reflect.Struct || reflect.PointerTo(ft).Implements(textUnmarshalerType) {
+			continue
+		}
+		if squash {
+			key = prefix
+		}
+		tagDefaults(ft, key, out)
+	}
+}
+
+var validate = newValidator()
+
+func newValidator() *validator.Validate {
+	v := validator.New(validator.WithRequiredStructEnabled())
+	// 错误中使用 mapstructure 键而不是字段名, 使其与配置文件中的键一致
+	v.RegisterTagNameFunc(func(field reflect.StructField) string {
+		if field.Tag.Get("mapstructure") == "-" {
+			return "-"
+		}
+		key, _ := configKey(field)
+		return key
+	})
+	return v
+}
+
+// validateStruct 按 validate 标签校验 target, 返回包含全部错误的 ValidationError
+func validateStruct(target any) error {
+	err := validate.Struct(target)
+	if err == nil {
+		return nil
+	}
+
+	var verrs validator.ValidationErrors
+	if !errors.As(err, &verrs) {
+		return fmt.Errorf("%w: %v", ErrInvalidConfig, err)
+	}
+	fields := make([]FieldError, 0, len(verrs))
+	for _, fe := range verrs {
+		// Namespace 以根结构体的类型名开头
+		_, key, _ := strings.Cut(fe.Namespace(), ".")
+		rule := fe.Tag()
+		if fe.Param() != "" {
+			rule += "=" + fe.Param()
+		}
+		fields = append(fields, FieldError{Key: key, Rule: rule, Value: fe.Value()})
+	}
+	return &ValidationError{Fields: fields}
+}
+
+// decodeTarget 将 v 反序列化到与 unmarshalTo 同类型的新值并校验, 通过后才由调用方写回 unmarshalTo,
+// 因此重新加载得到的无效配置不会覆盖正在使用的配置;
+// 新值不复制 unmarshalTo, 避免与其共享 map 与切片, 默认值只来自 default 标签与 WithDefault
+func (c *Config) decodeTarget(v *viper.Viper) (reflect.Value, error) {
+	if c.opts.unmarshalTo == nil {
+		return reflect.Value{}, nil
+	}
+
+	cur := reflect.ValueOf(c.opts.unmarshalTo)
+	if cur.Kind() != reflect.Pointer || cur.IsNil() {
+		return reflect.Value{}, fmt.Errorf("%w: target must be a non-nil pointer, got %T", ErrUnmarshal, c.opts.unmarshalTo)
+	}
+	target := reflect.New(cur.Elem().Type())
+
+	if err := decodeValidated(v, target.Interface()); err != nil {
+		return reflect.Value{}, err
+	}
+	return target, nil
+}
+
+// decodeValidated 将 v 反序列化到 target 并按 validate 标签校验
+func decodeValidated(v *viper.Viper, target any) error {
+	if err := v.Unmarshal(target, decoderOption()); err != nil {
+		return fmt.Errorf("%w: %v", ErrUnmarshal, err)
+	}
+	if reflect.Indirect(reflect.ValueOf(target)).Kind() == reflect.Struct {
+		return validateStruct(target)
+	}
+	return nil
+}
+
+// applyTarget 将校验通过的结果写回 unmarshalTo, 调用方需持有写锁
+func (c *Config) applyTarget(target reflect.Value) {
+	if target.IsValid() {
+		reflect.ValueOf(c.opts.unmarshalTo).Elem().Set(target.Elem())
+	}
+}
@@ -0,0 +1,160 @@
+package confv2
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+type testServer struct {
+	Host    string        `mapstructure:"host" default:"localhost"`
+	Port    int           `mapstructure:"port" default:"8080" validate:"min=1,max=65535"`
+	Timeout time.Duration `mapstructure:"timeout" default:"30s"`
+}
+
+type testAppConfig struct {
+	Name   string     `mapstructure:"name" validate:"required"`
+	Mode   string     `mapstructure:"mode" default:"dev" validate:"oneof=dev prod"`
+	Tags   []string   `mapstructure:"tags" default:"a,b"`
+	Server testServer `mapstructure:"server"`
+}
+
+func TestUnmarshal_Defaults(t *testing.T) {
+	var cfg testAppConfig
+	_, err := Init().
+		WithConfigReader(strings.NewReader("name: app\nserver:\n  port: 9090\n"), "yaml").
+		WithUnmarshal(&cfg).
+		Loading()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if cfg.Mode != "dev" || cfg.Server.Host != "localhost" || cfg.Server.Timeout != 30*time.Second {
+		t.Errorf("defaults not applied: %+v", cfg)
+	}
+	if cfg.Server.Port != 9090 {
+		t.Errorf("server.port = %d, want 9090", cfg.Server.Port)
+	}
+	if len(cfg.Tags) != 2 || cfg.Tags[1] != "b" {
+		t.Errorf("tags = %v", cfg.Tags)
+	}
+}
+
+func TestUnmarshal_Validate(t *testing.T) {
+	var cfg testAppConfig
+	_, err := Init().
+		WithConfigReader(strings.NewReader("nmae: typo\nmode: staging\nserver:\n  port: 0\n"), "yaml").
+		WithUnmarshal(&cfg).
+		Loading()
+
+	var verr *ValidationError
+	if !errors.As(err, &verr) {
+		t.Fatalf("got %v, want *ValidationError", err)
+	}
+	if !errors.Is(err, ErrInvalidConfig) {
+		t.Errorf("error should wrap ErrInvalidConfig")
+	}
+	keys := make([]string, 0, len(verr.Fields))
+	for _, f := range verr.Fields {
+		keys = append(keys, f.Key)
+	}
+	if got := strings.Join(keys, ","); got != "name,mode,server.port" {
+		t.Errorf("invalid keys = %s", got)
+	}
+}
+
+func TestUnmarshal_ReloadKeepsValid(t *testing.T) {
+	dir := t.TempDir()
+	file := filepath.Join(dir, "config.yaml")
+	writeFile(t, file, "name: app\n")
+
+	reloadErrs := make(chan error, 4)
+	var cfg testAppConfig
+	c, err := Init().
+		WithConfigFilePath(file, "yaml").
+		WithUnmarshal(&cfg).
+		WithWatch(true).
+		WithErrReadHandler(func(err error) error {
+			reloadErrs <- err
+			return err
+		}).
+		Loading()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := os.WriteFile(file, []byte("name: app\nmode: staging\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	select {
+	case err := <-reloadErrs:
+		if !errors.Is(err, ErrInvalidConfig) {
+			t.Fatalf("reload error = %v", err)
+		}
+	case <-time.After(3 * time.Second):
+		t.Fatal("invalid config was not rejected")
+	}
+	if got := c.Get("mode"); got != "dev" {
+		t.Errorf("mode = %v, invalid config should not be applied", got)
+	}
+}
+
+func TestUnmarshal_ReloadMapsAndSlices(t *testing.T) {
+	type config struct {
+		Name   string            `mapstructure:"name" validate:"required"`
+		Labels map[string]string `mapstructure:"labels"`
+		Hosts  []string          `mapstructure:"hosts"`
+	}
+
+	dir := t.TempDir()
+	file := filepath.Join(dir, "config.yaml")
+	writeFile(t, file, "name: app\nlabels:\n  a: '1'\n  b: '2'\nhosts: [x, y]\n")
+
+	reloadErrs := make(chan error, 4)
+	var cfg config
+	c, err := Init().
+		WithConfigFilePath(file, "yaml").
+		WithUnmarshal(&cfg).
+		WithWatch(true).
+		WithErrReadHandler(func(err error) error {
+			reloadErrs <- err
+			return err
+		}).
+		Loading()
+	if err != nil {
+		t.Fatal(err)
+	}
+	changed := make(chan struct{}, 4)
+	defer c.OnChange("**", func(ev ChangeEvent) { changed <- struct{}{} })()
+
+	// 校验失败的重新加载不能修改正在使用的 map 与切片
+	replaceFile(t, file, "labels:\n  c: '3'\nhosts: [z]\n")
+	select {
+	case err := <-reloadErrs:
+		if !errors.Is(err, ErrInvalidConfig) {
+			t.Fatalf("reload error = %v", err)
+		}
+	case <-time.After(3 * time.Second):
+		t.Fatal("invalid config was not rejected")
+	}
+	want := config{Name: "app", Labels: map[string]string{"a": "1", "b": "2"}, Hosts: []string{"x", "y"}}
+	if !reflect.DeepEqual(cfg, want) {
+		t.Errorf("after invalid reload cfg = %+v, want %+v", cfg, want)
+	}
+
+	// 成功的重新加载替换整个 map, 删除的键不再保留
+	replaceFile(t, file, "name: app\nlabels:\n  c: '3'\nhosts: [z]\n")
+	select {
+	case <-changed:
+	case <-time.After(3 * time.Second):
+		t.Fatal("valid config was not applied")
+	}
+	want = config{Name: "app", Labels: map[string]string{"c": "3"}, Hosts: []string{"z"}}
+	if !reflect.DeepEqual(cfg, want) {
+		t.Errorf("after reload cfg = %+v, want %+v", cfg, want)
+	}
+}
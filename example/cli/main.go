@@ -3,6 +3,7 @@ package main
 import (
 	"context"
 	"fmt"
+	"os"
 	"strings"
 
 	"github.com/chhz0/goose/cli"
@@ -13,9 +14,10 @@ func main() {
 
 	exec, err := cli.NewCommand(
 		&cli.Command{
-			Use:   "goosecli",
-			Short: "create a cli application.",
-			Long:  "goosecli is a cli toolkit based on cobra package.",
+			Use:            "goosecli",
+			Short:          "create a cli application.",
+			Long:           "goosecli is a cli toolkit based on cobra package.",
+			EnableLogFlags: true,
 			Inits: func() []func() {
 				var init1 = func() {
 					fmt.Println("goosecli init1....")
@@ -35,19 +37,19 @@ func main() {
 				return nil
 			},
 			FlagSet: rootOpts.flags(),
+			Config:  cli.NewConfig(rootOpts, "goosecli", ".", "$HOME/.goosecli").WithEnvPrefix("GOOSECLI"),
 			Commands: []cli.Commander{
 				newPrintCmd(),
 				newEchoCmd(),
 			},
 		},
+		cli.WithShell(),
 	)
 	if err != nil {
 		panic(err)
 	}
 
-	if err := exec.Execute(context.Background()); err != nil {
-		panic(err)
-	}
+	os.Exit(exec.Run(context.Background()))
 }
 
 func newPrintCmd() cli.Commander {
@@ -61,7 +63,7 @@ For many years people have printed back to the screen.`,
 			fmt.Printf("print: %s\n", args)
 			return nil
 		},
-		FlagSet: printOpts.flags(),
+		FlagSet: cli.FlagsFromStruct(printOpts),
 	}
 }
 
@@ -86,13 +88,18 @@ Echo works a lot like print, except it has a child command.`,
 func newTimeCmd() cli.Commander {
 	timeOpts := &TimesOption{}
 	return &cli.Command{
-		Use:   "times [# times] [string to echo]",
+		Use:   "times",
 		Short: "Echo anything to the screen more times.",
 		Long: `echo things multiple times back to the user by providing
 a count and a string.`,
+		Arguments: []cli.Arg{
+			{Name: "count", Usage: "number of times to echo", Type: cli.ArgInt},
+			{Name: "text", Usage: "string to echo", Variadic: true, Min: 1},
+		},
 		Run: func(ctx context.Context, args []string) error {
-			for i := 0; i < 5; i++ {
-				fmt.Println("Echo times: " + strings.Join(args, " "))
+			values := cli.ArgsFrom(ctx)
+			for i := 0; i < values.Int("count"); i++ {
+				fmt.Println("Echo times: " + strings.Join(values.Strings("text"), " "))
 			}
 			return nil
 		},
@@ -28,19 +28,8 @@ func (r *RootOption) flags() *cli.FlagSet {
 }
 
 type PrintOption struct {
-	Print string `mapstructure:"print"`
-	From  string `mapstructure:"from"`
-}
-
-func (p *PrintOption) flags() *cli.FlagSet {
-	var local = func(pfs *pflag.FlagSet) {
-		pfs.StringVarP(&p.Print, "print", "p", "print", "print")
-		pfs.StringVarP(&p.From, "from", "f", "from", "from")
-	}
-
-	return &cli.FlagSet{
-		Local: local,
-	}
+	Print string `mapstructure:"print" flag:",p" default:"print" usage:"print"`
+	From  string `mapstructure:"from" flag:",f" default:"from" usage:"from" env:"GOOSECLI_PRINT_FROM"`
 }
 
 type EchoOption struct {
@@ -2,6 +2,7 @@ package log
 
 import (
 	"io"
+	"path/filepath"
 	"strings"
 	"time"
 
@@ -55,9 +56,10 @@ func NewRotateByTime(cfg *RotateConfig) io.Writer {
 	if !cfg.LocalTime {
 		rotatelogs.WithClock(rotatelogs.UTC)
 	}
-	filename := strings.SplitN(cfg.Filename, ".", 2)
+	// 在文件名与扩展名之间插入时间, 目录中的 "." 不影响分割
+	ext := filepath.Ext(cfg.Filename)
 	logs, err := rotatelogs.New(
-		filename[0]+".%Y-%m-%d-%H-%M-%S."+filename[1],
+		strings.TrimSuffix(cfg.Filename, ext)+".%Y-%m-%d-%H-%M-%S"+ext,
 		opts...,
 	)
 	if err != nil {
@@ -50,14 +50,25 @@ func (s *ServerPlur) ShutdownAll(timeout time.Duration) error {
 	return s.eg.Wait()
 }
 
-func (s *ServerPlur) RunOrDie(sig ...os.Signal) error {
-	if err := s.StartAll(); err != nil {
+// Run 启动全部服务，在 ctx 取消后于 timeout 内关闭
+// 可直接使用 cli.WithSignals 传递给命令的 context，无需再单独监听信号
+func (s *ServerPlur) Run(ctx context.Context, timeout time.Duration) error {
+	errCh := make(chan error, 1)
+	go func() {
+		errCh <- s.StartAll()
+	}()
+
+	select {
+	case err := <-errCh:
 		return err
+	case <-ctx.Done():
+		return s.ShutdownAll(timeout)
 	}
+}
 
-	quit := make(chan os.Signal, 1)
-	signal.Notify(quit, sig...)
-	<-quit
+func (s *ServerPlur) RunOrDie(sig ...os.Signal) error {
+	ctx, stop := signal.NotifyContext(context.Background(), sig...)
+	defer stop()
 
-	return s.ShutdownAll(5 * time.Second)
+	return s.Run(ctx, 5*time.Second)
 }